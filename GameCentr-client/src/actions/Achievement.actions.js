@@ -1,4 +1,5 @@
 import axios from 'axios';
+import { api } from './Auth.actions';
 
 const backend_URI = window.location.hostname === 'localhost'
   ? "http://localhost:8080"
@@ -64,7 +65,7 @@ export const getUserAchievements = async (userId, gameCode = '', showHidden = fa
  */
 export const awardAchievement = async (userId, gameCode, achievementCode) => {
   try {
-    const response = await axios.post(`${backend_URI}/achievement/award`, {
+    const response = await api.post(`${backend_URI}/achievement/award`, {
       userId,
       gameCode,
       achievementCode
@@ -85,7 +86,7 @@ export const awardAchievement = async (userId, gameCode, achievementCode) => {
  */
 export const checkAchievementProgress = async (userId, gameCode, progress) => {
   try {
-    const response = await axios.post(`${backend_URI}/achievement/check-progress`, {
+    const response = await api.post(`${backend_URI}/achievement/check-progress`, {
       userId,
       gameCode,
       progress
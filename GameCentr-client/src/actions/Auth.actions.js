@@ -0,0 +1,69 @@
+import axios from "axios";
+import Cookies from "js-cookie";
+
+const backend_URI = window.location.hostname === 'localhost'
+  ? "http://localhost:8080"
+  : "https://sbd-numbrhunt.jpmd53.easypanel.host";
+
+// store the tokens returned by login and refresh
+export const setTokens = (accessToken, refreshToken) => {
+  Cookies.set("accessToken", accessToken, { path: '/' });
+  Cookies.set("refreshToken", refreshToken, { path: '/' });
+};
+
+// forget the tokens on logout
+export const clearTokens = () => {
+  Cookies.remove("accessToken", { path: '/' });
+  Cookies.remove("refreshToken", { path: '/' });
+};
+
+// Refresh tokens can only be used once, so concurrent requests that hit an
+// expired access token share a single refresh
+let refreshing = null;
+
+const refreshTokens = () => {
+  if (!refreshing) {
+    refreshing = axios.post(`${backend_URI}/auth/refresh`, {
+      refreshToken: Cookies.get("refreshToken"),
+    })
+      .then((response) => {
+        setTokens(response.data.accessToken, response.data.refreshToken);
+        return response.data.accessToken;
+      })
+      .catch((error) => {
+        clearTokens();
+        throw error;
+      })
+      .finally(() => {
+        refreshing = null;
+      });
+  }
+  return refreshing;
+};
+
+// api sends the access token with every request and refreshes it once when
+// the server rejects it as expired
+export const api = axios.create();
+
+api.interceptors.request.use((config) => {
+  const accessToken = Cookies.get("accessToken");
+  if (accessToken) {
+    config.headers.Authorization = `Bearer ${accessToken}`;
+  }
+  return config;
+});
+
+api.interceptors.response.use(
+  (response) => response,
+  async (error) => {
+    const config = error.config;
+    if (error.response?.status !== 401 || !config || config.retried || !Cookies.get("refreshToken")) {
+      throw error;
+    }
+
+    config.retried = true;
+    const accessToken = await refreshTokens();
+    config.headers.Authorization = `Bearer ${accessToken}`;
+    return api(config);
+  }
+);
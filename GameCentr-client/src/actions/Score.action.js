@@ -1,4 +1,5 @@
 import axios from "axios";
+import { api } from "./Auth.actions";
 
 const backend_URI = window.location.hostname === 'localhost'
     ? "http://localhost:8080"
@@ -11,10 +12,26 @@ const baseApiResponse = (data, isSuccess) => {
     };
 };
 
+// start a game session, to be sent as the session of the play's score
+export const startGameSession = async (game) => {
+    try {
+        const response = await api.post(
+            `${backend_URI}/game/${game}/session`
+        );
+
+        console.log("Response from Backend");
+        console.log(response.data);
+        return baseApiResponse(response.data.data, true);
+    } catch (error) {
+        console.error(error);
+        return baseApiResponse(null, false);
+    }
+};
+
 // post score
 export const createScorePost = async (input) => {
     try {
-        const response = await axios.post(
+        const response = await api.post(
             `${backend_URI}/game/${input.game}/score`, input
         );
 
@@ -63,7 +80,7 @@ export const getScoresByGame = async (game) => {
 export const addComment = async (input) => {
     try {
         const { game, scoreId, author, text } = input;
-        const response = await axios.post(
+        const response = await api.post(
             `${backend_URI}/game/${game}/score/${scoreId}/comment`,
             { author, text }
         );
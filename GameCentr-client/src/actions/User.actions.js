@@ -1,4 +1,5 @@
 import axios from "axios";
+import { api, setTokens, clearTokens } from "./Auth.actions";
 
 const backend_URI = window.location.hostname === 'localhost'
   ? "http://localhost:8080"
@@ -20,6 +21,7 @@ export const loginUser = async (input) => {
 
     console.log("Response from Backend");
     console.log(response.data);
+    setTokens(response.data.accessToken, response.data.refreshToken);
     return baseApiResponse(response.data.data, true);
   } catch (error) {
     console.error(error);
@@ -42,4 +44,15 @@ export const signUpUser = async (input) => {
     console.error(error);
     return baseApiResponse(null, false);
   }
-};
\ No newline at end of file
+};
+
+// logout
+export const logoutUser = async () => {
+  try {
+    await api.post(`${backend_URI}/auth/logout`);
+  } catch (error) {
+    console.error(error);
+  } finally {
+    clearTokens();
+  }
+};
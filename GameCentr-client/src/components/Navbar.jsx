@@ -1,10 +1,12 @@
 import { useCookies } from 'react-cookie';
 import { NavLink } from 'react-router-dom';
+import { logoutUser } from '../actions/User.actions';
 
 export default function Navbar() {
     const [cookies, setCookies] = useCookies(["username", "isLoggedIn", "score"]);
 
     const handleLogout = () => {
+        logoutUser();
         setCookies('score', 0, { path: '/' });
         setCookies('isLoggedIn', false, { path: '/' });
     };
@@ -1,8 +1,8 @@
-import React, { useState, useEffect } from 'react';
+import React, { useState, useEffect, useRef } from 'react';
 import Navbar from '../components/Navbar';
 import Footer from '../components/Footer';
 import { useCookies } from 'react-cookie';
-import { createScorePost } from "../actions/Score.action";
+import { createScorePost, startGameSession } from "../actions/Score.action";
 
 const COLORS = [
   { name: 'Red', hex: '#FF0000' },
@@ -143,6 +143,7 @@ const ColorGuess = () => {
   const [cooldown, setCooldown] = useState(false);
   const [cookies] = useCookies(["user_id"]);
   const [scorePosted, setScorePosted] = useState(false);
+  const session = useRef(null);
 
   useEffect(() => {
     if (gameOver && !scorePosted) {
@@ -156,11 +157,17 @@ const ColorGuess = () => {
       value: score,
       text: `Score: ${score}`,
       owner: cookies.user_id,
+      session: (await session.current).data?.session,
       game: "colorguess"
     });
     setScorePosted(true);
   };
 
+  // Every play is backed by a game session
+  useEffect(() => {
+    session.current = startGameSession("colorguess");
+  }, []);
+
   useEffect(() => {
     if (!gameOver && !win) {
       startRound(round);
@@ -196,6 +203,7 @@ const ColorGuess = () => {
   };
 
   const handleRestart = () => {
+    session.current = startGameSession("colorguess");
     setRound(1);
     setScore(0);
     setMistakes(0);
@@ -1,7 +1,7 @@
 import { useEffect, useRef, useState } from "react";
 import { useNavigate } from "react-router-dom";
 import { useCookies } from 'react-cookie';
-import { createScorePost } from "../actions/Score.action";
+import { createScorePost, startGameSession } from "../actions/Score.action";
 import Navbar from '../components/Navbar';
 import Footer from '../components/Footer';
 import { useAchievements } from '../context/AchievementContext';
@@ -11,6 +11,7 @@ export default function GuessGame() {
   const guessRef = useRef(null);
   const chancesRef = useRef(null);
   const scoreRef = useRef(null);
+  const session = useRef(null);
   const [randomNum, setRandomNum] = useState(Math.floor(Math.random() * 100));
   const [chance, setChance] = useState(10);
   const [disabled, setDisabled] = useState(false);
@@ -31,6 +32,7 @@ export default function GuessGame() {
 
   useEffect(() => {
     inputRef.current.focus(); // Focus input on mount
+    session.current = startGameSession("guess"); // Every posted score is backed by a game session
 
     if (!cookies.score) { // initialize score
       setCookies("score", 0, { path: '/' });
@@ -159,7 +161,7 @@ export default function GuessGame() {
     }
   };
 
-  const postScore = () => {
+  const postScore = async () => {
     // Create metadata for the score post
     const metadata = {
       target: randomNum,
@@ -172,6 +174,7 @@ export default function GuessGame() {
       value: score,
       text: scoreText,
       owner: cookies.user_id,
+      session: (await session.current).data?.session,
       game: "guess",
       metadata: metadata
     })
@@ -190,6 +193,8 @@ export default function GuessGame() {
           setScore(0);
           navigate("/post");
         } else {
+          // The session may have been used up, so post with a new one next time
+          session.current = startGameSession("guess");
           alert("Failed to post score!");
         }
       })
@@ -1,10 +1,10 @@
-import { useState, useEffect } from "react";
+import { useState, useEffect, useRef } from "react";
 import HangmanCanvas from "./HangmanCanvas";
 import "./HangmanGame.css";
 import Navbar from '../components/Navbar';
 import Footer from '../components/Footer';
 import { useCookies } from 'react-cookie';
-import { createScorePost } from "../actions/Score.action";
+import { createScorePost, startGameSession } from "../actions/Score.action";
 
 const words = ["REACT", "JAVASCRIPT", "DEVELOPER", "HANGMAN", "COMPONENT"];
 
@@ -13,6 +13,7 @@ const HangmanGame = () => {
     const [guessedLetters, setGuessedLetters] = useState([]);
     const [mistakes, setMistakes] = useState(0);
     const [scorePosted, setScorePosted] = useState(false);
+    const session = useRef(null);
     const [cookies] = useCookies(["user_id"]);
 
     useEffect(() => {
@@ -51,6 +52,8 @@ const HangmanGame = () => {
     };
 
     const resetGame = () => {
+        // Every play is backed by a game session
+        session.current = startGameSession("hangman");
         setWord(chooseRandomWord());
         setGuessedLetters([]);
         setMistakes(0);
@@ -66,6 +69,7 @@ const HangmanGame = () => {
                 value: scoreValue,
                 text: scoreText,
                 owner: cookies.user_id,
+                session: (await session.current).data?.session,
                 game: "hangman"
             });
             setScorePosted(true);
@@ -1,8 +1,8 @@
-import React, { useState, useEffect } from 'react';
+import React, { useState, useEffect, useRef } from 'react';
 import Navbar from '../components/Navbar';
 import Footer from '../components/Footer';
 import { useCookies } from 'react-cookie';
-import { createScorePost } from "../actions/Score.action";
+import { createScorePost, startGameSession } from "../actions/Score.action";
 
 const CARD_PAIRS = [
   '🍎', '🍌', '🍇', '🍉', '🍒', '🍋', '🍓', '🥝'
@@ -28,6 +28,7 @@ const MemoryMatch = () => {
   const [cooldown, setCooldown] = useState(false);
   const [cookies] = useCookies(["user_id"]);
   const [scorePosted, setScorePosted] = useState(false);
+  const session = useRef(null);
 
   useEffect(() => {
     if (gameOver && !scorePosted) {
@@ -44,6 +45,7 @@ const MemoryMatch = () => {
       value: scoreValue,
       text: scoreText,
       owner: cookies.user_id,
+      session: (await session.current).data?.session,
       game: "memorymatch"
     });
     setScorePosted(true);
@@ -70,6 +72,8 @@ const MemoryMatch = () => {
   }, [matched, cards]);
 
   const startGame = () => {
+    // Every play is backed by a game session
+    session.current = startGameSession("memorymatch");
     const deck = shuffle([...CARD_PAIRS, ...CARD_PAIRS]).map((icon, i) => ({ icon, id: i }));
     setCards(deck);
     setFlipped([]);
@@ -2,7 +2,7 @@ import React, { useState, useEffect, useRef } from 'react';
 import Navbar from '../components/Navbar';
 import Footer from '../components/Footer';
 import { useCookies } from 'react-cookie';
-import { createScorePost } from "../actions/Score.action";
+import { createScorePost, startGameSession } from "../actions/Score.action";
 
 const ARROWS = [
   { key: 'ArrowUp', label: '↑', color: 'bg-blue-400' },
@@ -32,6 +32,7 @@ const PatternRepeater = () => {
   const timeoutRef = useRef();
   const [cookies] = useCookies(["user_id"]);
   const [scorePosted, setScorePosted] = useState(false);
+  const session = useRef(null);
 
   useEffect(() => {
     if ((gameOver || win) && !scorePosted) {
@@ -45,6 +46,7 @@ const PatternRepeater = () => {
       value: score,
       text: win ? "Win!" : "Game Over",
       owner: cookies.user_id,
+      session: (await session.current).data?.session,
       game: "patternrepeater"
     });
     setScorePosted(true);
@@ -121,6 +123,8 @@ const PatternRepeater = () => {
   }, [showing, gameOver, win]);
 
   const startNewGame = () => {
+    // Every play is backed by a game session
+    session.current = startGameSession("patternrepeater");
     setPattern([getRandomArrow().key]);
     setUserInput([]);
     setRound(1);
@@ -3,7 +3,7 @@ import './PongGame.css'; // Import the CSS file for styling
 import Navbar from '../components/Navbar';
 import Footer from '../components/Footer';
 import { useCookies } from 'react-cookie';
-import { createScorePost } from "../actions/Score.action";
+import { createScorePost, startGameSession } from "../actions/Score.action";
 
 const PongGame = () => {
     const initialBallState = { x: 300, y: 200, speedX: 5, speedY: 5 };
@@ -18,6 +18,7 @@ const PongGame = () => {
     const [bounces, setBounces] = useState(0);
     const [cookies] = useCookies(["user_id"]);
     const [scorePosted, setScorePosted] = useState(false);
+    const session = useRef(null);
 
     useEffect(() => {
         if (gameOver && !scorePosted) {
@@ -31,6 +32,7 @@ const PongGame = () => {
         value: bounces,
         text: `Bounces: ${bounces}`,
         owner: cookies.user_id,
+        session: (await session.current).data?.session,
         game: "pong"
         });
         setScorePosted(true);
@@ -158,10 +160,16 @@ const PongGame = () => {
     }, [gameOver]);
 
     const startGame = () => {
+        // Every play is backed by a game session, started with the play
+        // rather than when it is resumed
+        if (!session.current) {
+            session.current = startGameSession("pong");
+        }
         setGameRunning(true);
     };
 
     const restartGame = () => {
+        session.current = null;
         setBall(initialBallState);
         setPaddles(initialPaddleState);
         setGameOver(false);
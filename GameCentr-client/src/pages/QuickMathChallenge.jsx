@@ -2,7 +2,7 @@ import React, { useState, useEffect, useRef } from 'react';
 import Navbar from '../components/Navbar';
 import Footer from '../components/Footer';
 import { useCookies } from 'react-cookie';
-import { createScorePost } from "../actions/Score.action";
+import { createScorePost, startGameSession } from "../actions/Score.action";
 
 const OPERATORS = [
   { op: '+', fn: (a, b) => a + b },
@@ -61,6 +61,7 @@ const QuickMathChallenge = () => {
   const timerRef = useRef();
   const [cookies] = useCookies(["user_id"]);
   const [scorePosted, setScorePosted] = useState(false);
+  const session = useRef(null);
 
   useEffect(() => {
     if (gameOver && !scorePosted) {
@@ -74,10 +75,16 @@ const QuickMathChallenge = () => {
       value: score,
       text: `Score: ${score}`,
       owner: cookies.user_id,
+      session: (await session.current).data?.session,
       game: "quickmath"
     });
     setScorePosted(true);
   };
+
+  // Every play is backed by a game session
+  useEffect(() => {
+    session.current = startGameSession("quickmath");
+  }, []);
   
   useEffect(() => {
     if (gameOver || win) return;
@@ -152,6 +159,7 @@ const QuickMathChallenge = () => {
   };
 
   const handleRestart = () => {
+    session.current = startGameSession("quickmath");
     setRound(1);
     setScore(0);
     setMistakes(0);
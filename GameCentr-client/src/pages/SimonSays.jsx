@@ -1,8 +1,8 @@
-import React, { useState, useEffect } from 'react';
+import React, { useState, useEffect, useRef } from 'react';
 import Navbar from '../components/Navbar';
 import Footer from '../components/Footer';
 import { useCookies } from 'react-cookie';
-import { createScorePost } from "../actions/Score.action";
+import { createScorePost, startGameSession } from "../actions/Score.action";
 
 const COLORS = ['red', 'green', 'blue', 'yellow'];
 
@@ -21,6 +21,7 @@ const SimonSays = () => {
   const [cooldown, setCooldown] = useState(false);
   const [cookies] = useCookies(["user_id"]);
   const [scorePosted, setScorePosted] = useState(false);
+  const session = useRef(null);
 
   useEffect(() => {
     if (gameOver && !scorePosted) {
@@ -34,12 +35,15 @@ const SimonSays = () => {
       value: round,
       text: `Round: ${round}`,
       owner: cookies.user_id,
+      session: (await session.current).data?.session,
       game: "simonsays"
     });
     setScorePosted(true);
   };
   
+  // Every play is backed by a game session
   useEffect(() => {
+    session.current = startGameSession("simonsays");
     if (!gameOver) startNewRound();
     // eslint-disable-next-line
   }, []);
@@ -93,6 +97,7 @@ const SimonSays = () => {
   };
 
   const handleRestart = () => {
+    session.current = startGameSession("simonsays");
     setSequence([]);
     setUserInput([]);
     setRound(1);
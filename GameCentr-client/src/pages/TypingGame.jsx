@@ -1,13 +1,14 @@
 //TypingGame.js
 import React, {
     useState,
-    useEffect
+    useEffect,
+    useRef
 } from 'react';
 import './TypingGame.css';
 import Navbar from '../components/Navbar';
 import Footer from '../components/Footer';
 import { useCookies } from 'react-cookie';
-import { createScorePost } from "../actions/Score.action";
+import { createScorePost, startGameSession } from "../actions/Score.action";
 
 const sentences = [
     "The quick brown fox jumps over the lazy dog.",
@@ -26,11 +27,13 @@ const TypingGame = () => {
     const [sentence, setSentence] = useState('');
     const [input, setInput] = useState('');
     const [score, setScore] = useState(0);
+    const [wordsTyped, setWordsTyped] = useState(0);
     const [time, setTime] = useState(60);
     const [isGameOver, setIsGameOver] = useState(false);
     const [isGameStarted, setIsGameStarted] = useState(false);
     const [cookies] = useCookies(["user_id"]);
     const [scorePosted, setScorePosted] = useState(false);
+    const session = useRef(null);
 
     useEffect(() => {
         if (isGameOver && !scorePosted) {
@@ -44,7 +47,9 @@ const TypingGame = () => {
             value: score,
             text: `Typing Score: ${score}`,
             owner: cookies.user_id,
-            game: "typing"
+            session: (await session.current).data?.session,
+            game: "typing",
+            metadata: { wordsTyped: wordsTyped }
         });
         setScorePosted(true);
     };
@@ -83,13 +88,18 @@ const TypingGame = () => {
             setInput(e.target.value);
             if (e.target.value === sentence) {
                 setScore((prevScore) => prevScore + 1);
+                setWordsTyped((prevWords) => prevWords + sentence.split(' ').length);
                 setInput('');
                 generateRandomSentence();
             }
         }
     };
 
-    const handleStartGame = () => {
+    const handleStartGame = async () => {
+        // Every play is backed by a game session. The clock only starts once
+        // it is issued, so the play also lasts the full minute for the server.
+        session.current = startGameSession("typing");
+        await session.current;
         setIsGameStarted(true);
     };
 
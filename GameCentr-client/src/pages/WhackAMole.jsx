@@ -2,7 +2,7 @@ import React, { useState, useEffect, useRef } from 'react';
 import Navbar from '../components/Navbar';
 import Footer from '../components/Footer';
 import { useCookies } from 'react-cookie';
-import { createScorePost } from "../actions/Score.action";
+import { createScorePost, startGameSession } from "../actions/Score.action";
 
 const MOLE_COUNT = 9;
 const GAME_TIME = 30; // seconds
@@ -26,6 +26,7 @@ const WhackAMole = () => {
   const timerRef = useRef();
   const [cookies] = useCookies(["user_id"]);
   const [scorePosted, setScorePosted] = useState(false);
+  const session = useRef(null);
 
   useEffect(() => {
     if (!gameActive && timeLeft === 0 && !scorePosted) {
@@ -39,7 +40,9 @@ const WhackAMole = () => {
       value: score,
       text: `Score: ${score}`,
       owner: cookies.user_id,
-      game: "whackamole"
+      session: (await session.current).data?.session,
+      game: "whackamole",
+      metadata: { molesWhacked: score }
     });
     setScorePosted(true);
   };
@@ -90,7 +93,11 @@ const WhackAMole = () => {
     }
   };
 
-  const startGame = () => {
+  const startGame = async () => {
+    // Every play is backed by a game session. The clock only starts once it is
+    // issued, so the play also lasts the full game time for the server.
+    session.current = startGameSession("whackamole");
+    await session.current;
     setScore(0);
     setTimeLeft(GAME_TIME);
     setGameActive(true);
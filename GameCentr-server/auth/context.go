@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	userIDKey    = "auth.userID"
+	usernameKey  = "auth.username"
+	sessionIDKey = "auth.sessionID"
+)
+
+// SetCurrentUser stores the authenticated caller on the request context
+func SetCurrentUser(c *gin.Context, claims *Claims) {
+	userID, _ := primitive.ObjectIDFromHex(claims.UserID)
+	sessionID, _ := primitive.ObjectIDFromHex(claims.SessionID)
+	c.Set(userIDKey, userID)
+	c.Set(usernameKey, claims.Username)
+	c.Set(sessionIDKey, sessionID)
+}
+
+// CurrentUserID returns the ID of the authenticated caller, if any
+func CurrentUserID(c *gin.Context) (primitive.ObjectID, bool) {
+	value, exists := c.Get(userIDKey)
+	if !exists {
+		return primitive.NilObjectID, false
+	}
+	userID, ok := value.(primitive.ObjectID)
+	return userID, ok
+}
+
+// CurrentUsername returns the username of the authenticated caller, if any
+func CurrentUsername(c *gin.Context) string {
+	return c.GetString(usernameKey)
+}
+
+// CurrentSessionID returns the session the caller's access token belongs to, if any
+func CurrentSessionID(c *gin.Context) (primitive.ObjectID, bool) {
+	value, exists := c.Get(sessionIDKey)
+	if !exists {
+		return primitive.NilObjectID, false
+	}
+	sessionID, ok := value.(primitive.ObjectID)
+	return sessionID, ok
+}
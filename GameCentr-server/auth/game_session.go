@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"netgames-go-server/models"
+)
+
+// ErrInvalidGameSessionToken is returned for malformed game session tokens
+var ErrInvalidGameSessionToken = errors.New("invalid game session token")
+
+// SignGameSession returns the token a client presents when submitting a score
+func SignGameSession(session models.GameSession) string {
+	return session.ID.Hex() + "." + gameSessionSignature(session)
+}
+
+// GameSessionIDFromToken extracts the session ID from a game session token
+// without checking its signature
+func GameSessionIDFromToken(token string) (primitive.ObjectID, error) {
+	sessionHex, _, found := strings.Cut(token, ".")
+	if !found {
+		return primitive.NilObjectID, ErrInvalidGameSessionToken
+	}
+	sessionID, err := primitive.ObjectIDFromHex(sessionHex)
+	if err != nil {
+		return primitive.NilObjectID, ErrInvalidGameSessionToken
+	}
+	return sessionID, nil
+}
+
+// VerifyGameSession reports whether the token was issued by this server for the session
+func VerifyGameSession(token string, session models.GameSession) bool {
+	return hmac.Equal([]byte(token), []byte(SignGameSession(session)))
+}
+
+// gameSessionSignature signs every field the server relies on when accepting a score
+func gameSessionSignature(session models.GameSession) string {
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write([]byte("game-session"))
+	for _, part := range []string{
+		session.ID.Hex(),
+		session.UserID.Hex(),
+		session.GameCode,
+		strconv.FormatInt(session.IssuedAt.UnixMilli(), 10),
+		strconv.FormatInt(session.Seed, 10),
+	} {
+		mac.Write([]byte{0})
+		mac.Write([]byte(part))
+	}
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
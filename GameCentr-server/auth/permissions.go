@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"netgames-go-server/db"
+	"netgames-go-server/models"
+)
+
+// Permission names an action that is restricted to certain roles
+type Permission string
+
+const (
+	PermAwardAchievements  Permission = "achievements:award"
+	PermManageAchievements Permission = "achievements:manage"
+	PermManageGameTypes    Permission = "game_types:manage"
+	PermManageSeasons      Permission = "seasons:manage"
+	PermModerateComments   Permission = "comments:moderate"
+	PermManageUsers        Permission = "users:manage"
+)
+
+// rolePermissions lists what each role is allowed to do. Players have no
+// special permissions; admins can do everything.
+var rolePermissions = map[string][]Permission{
+	models.RolePlayer: {},
+	models.RoleModerator: {
+		PermModerateComments,
+	},
+	models.RoleAdmin: {
+		PermAwardAchievements,
+		PermManageAchievements,
+		PermManageGameTypes,
+		PermManageSeasons,
+		PermModerateComments,
+		PermManageUsers,
+	},
+}
+
+// HasPermission reports whether the given role grants the permission
+func HasPermission(role string, permission Permission) bool {
+	return slices.Contains(rolePermissions[role], permission)
+}
+
+// LookupRole loads the current role of a user. Roles are always read from the
+// database so that a demotion takes effect immediately.
+func LookupRole(ctx context.Context, userID primitive.ObjectID) (string, error) {
+	var user models.User
+	err := db.UserColl.FindOne(ctx, bson.M{"_id": userID}).Decode(&user)
+	if err != nil {
+		return "", err
+	}
+	return user.EffectiveRole(), nil
+}
@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"netgames-go-server/db"
+	"netgames-go-server/models"
+)
+
+// RefreshTokenTTL is how long a session stays alive without being refreshed
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+var (
+	// ErrInvalidRefreshToken is returned for unknown or malformed refresh tokens
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrSessionRevoked is returned when the session was revoked or has expired
+	ErrSessionRevoked = errors.New("session has been revoked or has expired")
+	// ErrRefreshTokenReused is returned when an already rotated token is replayed
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected, session revoked")
+)
+
+// CreateSession starts a new session for the user and returns its first refresh token
+func CreateSession(ctx context.Context, userID primitive.ObjectID, userAgent, ipAddress string) (models.Session, string, error) {
+	now := time.Now()
+	session := models.Session{
+		ID:            primitive.NewObjectID(),
+		UserID:        userID,
+		RotatedHashes: []string{},
+		UserAgent:     userAgent,
+		IPAddress:     ipAddress,
+		CreatedAt:     now,
+		LastUsedAt:    now,
+		ExpiresAt:     now.Add(RefreshTokenTTL),
+	}
+
+	refreshToken, err := newRefreshToken(session.ID)
+	if err != nil {
+		return models.Session{}, "", err
+	}
+	session.RefreshTokenHash = hashRefreshToken(refreshToken)
+
+	if _, err := db.SessionColl.InsertOne(ctx, session); err != nil {
+		return models.Session{}, "", err
+	}
+
+	return session, refreshToken, nil
+}
+
+// RotateSession exchanges a refresh token for a new one. Presenting a token
+// that was already rotated revokes the whole session.
+func RotateSession(ctx context.Context, refreshToken string) (models.Session, string, error) {
+	sessionID, err := sessionIDFromRefreshToken(refreshToken)
+	if err != nil {
+		return models.Session{}, "", err
+	}
+
+	var session models.Session
+	err = db.SessionColl.FindOne(ctx, bson.M{"_id": sessionID}).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return models.Session{}, "", ErrInvalidRefreshToken
+		}
+		return models.Session{}, "", err
+	}
+
+	now := time.Now()
+	if !session.IsActive(now) {
+		return models.Session{}, "", ErrSessionRevoked
+	}
+
+	presentedHash := hashRefreshToken(refreshToken)
+	if presentedHash != session.RefreshTokenHash {
+		if slices.Contains(session.RotatedHashes, presentedHash) {
+			if err := RevokeSession(ctx, session.ID, "refresh token reuse"); err != nil {
+				return models.Session{}, "", err
+			}
+			return models.Session{}, "", ErrRefreshTokenReused
+		}
+		return models.Session{}, "", ErrInvalidRefreshToken
+	}
+
+	newToken, err := newRefreshToken(session.ID)
+	if err != nil {
+		return models.Session{}, "", err
+	}
+	newHash := hashRefreshToken(newToken)
+
+	// Only rotate if nobody else rotated this token in the meantime
+	result, err := db.SessionColl.UpdateOne(
+		ctx,
+		bson.M{"_id": session.ID, "refresh_token_hash": presentedHash, "revoked_at": nil},
+		bson.M{
+			"$set": bson.M{
+				"refresh_token_hash": newHash,
+				"last_used_at":       now,
+				"expires_at":         now.Add(RefreshTokenTTL),
+			},
+			"$push": bson.M{"rotated_hashes": presentedHash},
+		},
+	)
+	if err != nil {
+		return models.Session{}, "", err
+	}
+	if result.MatchedCount == 0 {
+		// A concurrent request already used this token
+		if err := RevokeSession(ctx, session.ID, "refresh token reuse"); err != nil {
+			return models.Session{}, "", err
+		}
+		return models.Session{}, "", ErrRefreshTokenReused
+	}
+
+	session.RefreshTokenHash = newHash
+	session.RotatedHashes = append(session.RotatedHashes, presentedHash)
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(RefreshTokenTTL)
+
+	return session, newToken, nil
+}
+
+// RevokeSession marks a single session as revoked
+func RevokeSession(ctx context.Context, sessionID primitive.ObjectID, reason string) error {
+	_, err := db.SessionColl.UpdateOne(
+		ctx,
+		bson.M{"_id": sessionID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now(), "revoked_reason": reason}},
+	)
+	return err
+}
+
+// RevokeAllSessions revokes every active session of a user and returns how many were revoked
+func RevokeAllSessions(ctx context.Context, userID primitive.ObjectID, reason string) (int64, error) {
+	result, err := db.SessionColl.UpdateMany(
+		ctx,
+		bson.M{"user_id": userID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now(), "revoked_reason": reason}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// IsSessionActive reports whether the session behind an access token is still usable
+func IsSessionActive(ctx context.Context, sessionID primitive.ObjectID) (bool, error) {
+	var session models.Session
+	err := db.SessionColl.FindOne(ctx, bson.M{"_id": sessionID}).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return false, nil
+		}
+		return false, err
+	}
+	return session.IsActive(time.Now()), nil
+}
+
+// newRefreshToken creates an opaque refresh token bound to a session
+func newRefreshToken(sessionID primitive.ObjectID) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return sessionID.Hex() + "." + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// hashRefreshToken returns the form of a refresh token that is persisted
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// sessionIDFromRefreshToken extracts the session a refresh token belongs to
+func sessionIDFromRefreshToken(token string) (primitive.ObjectID, error) {
+	sessionHex, _, found := strings.Cut(token, ".")
+	if !found {
+		return primitive.NilObjectID, ErrInvalidRefreshToken
+	}
+	sessionID, err := primitive.ObjectIDFromHex(sessionHex)
+	if err != nil {
+		return primitive.NilObjectID, ErrInvalidRefreshToken
+	}
+	return sessionID, nil
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"netgames-go-server/models"
+)
+
+// AccessTokenTTL is how long an issued access token stays valid
+const AccessTokenTTL = 15 * time.Minute
+
+const tokenIssuer = "netgames"
+
+var (
+	// ErrInvalidToken is returned when a token is malformed, expired or badly signed
+	ErrInvalidToken = errors.New("invalid or expired token")
+
+	signingKey []byte
+)
+
+// Claims are the custom JWT claims carried by an access token
+type Claims struct {
+	UserID    string `json:"uid"`
+	Username  string `json:"username"`
+	SessionID string `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// Init loads the token signing key from the environment
+func Init() {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		log.Fatal("JWT_SECRET environment variable not set")
+	}
+	signingKey = []byte(secret)
+}
+
+// IssueAccessToken creates a signed access token for the given user and session
+func IssueAccessToken(user models.User, sessionID primitive.ObjectID) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(AccessTokenTTL)
+
+	claims := Claims{
+		UserID:    user.ID.Hex(),
+		Username:  user.Username,
+		SessionID: sessionID.Hex(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokenIssuer,
+			Subject:   user.ID.Hex(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString(signingKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return signed, expiresAt, nil
+}
+
+// ParseAccessToken validates a signed access token and returns its claims
+func ParseAccessToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return signingKey, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	if _, err := primitive.ObjectIDFromHex(claims.UserID); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if _, err := primitive.ObjectIDFromHex(claims.SessionID); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
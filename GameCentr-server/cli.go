@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"netgames-go-server/db"
+	"netgames-go-server/services"
+	"sort"
+	"time"
+)
+
+// commands are maintenance tasks that run instead of the server when the
+// binary is started as ./app <command> [flags]
+var commands = map[string]func(args []string) error{
+	"create-admin":         createAdminCommand,
+	"rebuild-leaderboards": rebuildLeaderboardsCommand,
+	"recompute-ratings":    recomputeRatingsCommand,
+}
+
+// runCommand runs a maintenance command and returns the process exit code
+func runCommand(args []string) int {
+	command, ok := commands[args[0]]
+	if !ok {
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		log.Printf("Unknown command %q, available commands: %v", args[0], names)
+		return 2
+	}
+
+	// Connect to MongoDB
+	db.ConnectDB()
+	defer db.DisconnectDB()
+
+	if err := command(args[1:]); err != nil {
+		log.Printf("Command %s failed: %v", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// createAdminCommand promotes a user to admin, creating it if needed
+func createAdminCommand(args []string) error {
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	username := flags.String("username", "", "username of the admin")
+	password := flags.String("password", "", "password used when the user has to be created")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	return db.EnsureAdmin(db.Client, db.DatabaseName, *username, *password)
+}
+
+// rebuildLeaderboardsCommand recomputes the materialized leaderboards of every
+// game, e.g. to backfill them from existing scores
+func rebuildLeaderboardsCommand(args []string) error {
+	flags := flag.NewFlagSet("rebuild-leaderboards", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	return services.RebuildAllLeaderboards(ctx)
+}
+
+// recomputeRatingsCommand rebuilds every skill rating by replaying the scores collection
+func recomputeRatingsCommand(args []string) error {
+	flags := flag.NewFlagSet("recompute-ratings", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	return services.RecomputeRatings(ctx)
+}
@@ -4,14 +4,18 @@ import (
 	"context"
 	"log"
 	"net/http"
+	"netgames-go-server/auth"
 	"netgames-go-server/db"
 	"netgames-go-server/models"
+	"netgames-go-server/services"
+	"slices"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // GetAllAchievements returns all achievements
@@ -44,13 +48,20 @@ func GetAllAchievements(c *gin.Context) {
 		}
 	}
 
-	// Only show non-hidden achievements unless showHidden is true
+	// Only show non-hidden achievements unless showHidden is true. Chained
+	// achievements stay hidden until their prerequisites are unlocked.
 	if !showHidden {
 		filter["is_hidden"] = false
+		filter["prerequisites.0"] = bson.M{"$exists": false}
+	}
+
+	// Retired achievements are only listed on request
+	if c.Query("includeRetired") != "true" {
+		filter["retired"] = bson.M{"$ne": true}
 	}
 
 	// Find achievements
-	cursor, err := db.AchievementColl.Find(context.Background(), filter)
+	cursor, err := db.AchievementColl.Find(context.Background(), filter, achievementOrder())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get achievements"})
 		return
@@ -63,6 +74,18 @@ func GetAllAchievements(c *gin.Context) {
 		return
 	}
 
+	// Add each achievement's point value and rarity
+	unlockPercents, err := services.AchievementUnlockPercents(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get achievement rarity"})
+		return
+	}
+	for i := range achievements {
+		percent := unlockPercents[achievements[i].ID]
+		achievements[i].Points = achievements[i].PointValue()
+		achievements[i].UnlockPercent = &percent
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    achievements,
@@ -106,12 +129,7 @@ func GetUserAchievements(c *gin.Context) {
 		}
 	}
 
-	// Only show non-hidden achievements unless showHidden is true
-	if !showHidden {
-		achievementFilter["is_hidden"] = false
-	}
-
-	cursor, err := db.AchievementColl.Find(context.Background(), achievementFilter)
+	cursor, err := db.AchievementColl.Find(context.Background(), achievementFilter, achievementOrder())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get achievements"})
 		return
@@ -148,6 +166,14 @@ func GetUserAchievements(c *gin.Context) {
 		unlockedAchievements[ua.AchievementID.Hex()] = ua
 	}
 
+	// Track unlocked achievements by code to resolve prerequisites
+	unlockedCodes := make(map[string]bool)
+	for _, a := range achievements {
+		if _, unlocked := unlockedAchievements[a.ID.Hex()]; unlocked {
+			unlockedCodes[services.AchievementKey(a.GameCode, a.Code)] = true
+		}
+	}
+
 	// Get game types for game names
 	var gameTypes []models.GameType
 	gameTypeCursor, err := db.GameTypeColl.Find(context.Background(), bson.M{})
@@ -169,63 +195,81 @@ func GetUserAchievements(c *gin.Context) {
 	}
 	gameNames["all"] = "Global"
 
+	// Get the user's progress towards counter achievements
+	progress, err := services.GetAchievementProgress(context.Background(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get achievement progress"})
+		return
+	}
+
+	// Get how many active users unlocked each achievement
+	unlockPercents, err := services.AchievementUnlockPercents(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get achievement rarity"})
+		return
+	}
+
 	// Combine achievements with user achievement data
 	var achievementsWithDetails []models.AchievementWithDetails
 	for _, a := range achievements {
 		ua, unlocked := unlockedAchievements[a.ID.Hex()]
-		
-		// Skip hidden achievements that are not unlocked
-		if a.IsHidden && !unlocked && !showHidden {
+
+		// Retired achievements only remain on the profiles of users who unlocked them
+		if a.Retired && !unlocked {
 			continue
 		}
 
-		// Get game name
-		gameName, ok := gameNames[a.GameCode]
-		if !ok {
-			gameName = a.GameCode
+		// Skip hidden achievements that are not unlocked, and chained
+		// achievements whose prerequisites are not unlocked yet
+		if !unlocked && !showHidden && (a.IsHidden || !services.PrerequisitesMet(a, unlockedCodes)) {
+			continue
 		}
 
-		// Create achievement with details
-		awd := models.AchievementWithDetails{
-			ID:          a.ID,
-			GameCode:    a.GameCode,
-			GameName:    gameName,
-			Code:        a.Code,
-			Title:       a.Title,
-			Description: a.Description,
-			Icon:        a.Icon,
-			Difficulty:  a.Difficulty,
-			IsHidden:    a.IsHidden,
-			IsUnlocked:  unlocked,
+		// Create achievement with details, adding the awarded time if unlocked
+		var awardedAt *time.Time
+		if unlocked {
+			awardedAt = &ua.AwardedAt
 		}
+		awd := services.AchievementDetails(a, gameNames, awardedAt)
 
-		// Add awarded time if unlocked
-		if unlocked {
-			awd.AwardedAt = &ua.AwardedAt
+		// Show how far the user got towards counter achievements
+		var record *models.AchievementProgress
+		if p, ok := progress[a.ID]; ok {
+			record = &p
 		}
+		awd = services.WithProgress(awd, a.Criteria, record)
+
+		percent := unlockPercents[a.ID]
+		awd.UnlockPercent = &percent
 
 		achievementsWithDetails = append(achievementsWithDetails, awd)
 	}
 
+	// Present the tiers of each tier group as a single achievement
+	achievementsWithDetails = services.GroupTiers(achievementsWithDetails)
+
 	// Get user data
 	var user models.User
 	err = db.UserColl.FindOne(context.Background(), bson.M{"_id": userID}).Decode(&user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user data"})
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user data"})
+		}
 		return
 	}
 
 	// Get user stats
-	stats := map[string]interface{}{
-		"totalGamesPlayed": 0,
-		"totalAchievements": len(userAchievements),
-		"favoriteGame": "",
-		"highestScore": 0,
+	stats, err := services.ProfileStats(context.Background(), userID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user stats"})
+		return
 	}
 
-	// Create user profile
+	// Create user profile without sensitive user fields
 	userProfile := models.UserProfile{
-		User:         user,
+		User:         user.ToResponse(),
 		Achievements: achievementsWithDetails,
 		Stats:        stats,
 	}
@@ -236,13 +280,15 @@ func GetUserAchievements(c *gin.Context) {
 	})
 }
 
-// AwardAchievement awards an achievement to a user
+// AwardAchievement lets an admin award an achievement to any user
 func AwardAchievement(c *gin.Context) {
 	// Parse request body
 	var input struct {
-		UserID        string `json:"userId" binding:"required"`
-		GameCode      string `json:"gameCode" binding:"required"`
+		UserID          string `json:"userId" binding:"required"`
+		GameCode        string `json:"gameCode" binding:"required"`
 		AchievementCode string `json:"achievementCode" binding:"required"`
+		Source          string `json:"source"` // manual (default) or import
+		Reason          string `json:"reason"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -250,6 +296,14 @@ func AwardAchievement(c *gin.Context) {
 		return
 	}
 
+	if input.Source == "" {
+		input.Source = models.AuditSourceManual
+	}
+	if input.Source != models.AuditSourceManual && input.Source != models.AuditSourceImport {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "source must be manual or import"})
+		return
+	}
+
 	// Convert user ID to ObjectID
 	userID, err := primitive.ObjectIDFromHex(input.UserID)
 	if err != nil {
@@ -257,6 +311,17 @@ func AwardAchievement(c *gin.Context) {
 		return
 	}
 
+	// Make sure the target user exists
+	count, err := db.UserColl.CountDocuments(context.Background(), bson.M{"_id": userID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find user"})
+		return
+	}
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
 	// Find the achievement
 	var achievement models.Achievement
 	err = db.AchievementColl.FindOne(
@@ -272,8 +337,13 @@ func AwardAchievement(c *gin.Context) {
 		return
 	}
 
+	if achievement.Retired {
+		c.JSON(http.StatusConflict, gin.H{"error": "Achievement is retired"})
+		return
+	}
+
 	// Check if the user already has this achievement
-	count, err := db.UserAchievementColl.CountDocuments(
+	count, err = db.UserAchievementColl.CountDocuments(
 		context.Background(),
 		bson.M{"user_id": userID, "achievement_id": achievement.ID},
 	)
@@ -312,6 +382,28 @@ func AwardAchievement(c *gin.Context) {
 	// Get the inserted ID
 	userAchievement.ID = result.InsertedID.(primitive.ObjectID)
 
+	// A manual award lifts an earlier revocation
+	if err := services.ClearRevocation(context.Background(), userID, achievement.ID); err != nil {
+		log.Printf("Error clearing revocation of achievement %s: %v", achievement.Code, err)
+	}
+
+	// Record who awarded it
+	actorID, _ := auth.CurrentUserID(c)
+	err = services.RecordAchievementAudit(context.Background(), models.AchievementAudit{
+		UserID:        userID,
+		AchievementID: achievement.ID,
+		GameCode:      achievement.GameCode,
+		Code:          achievement.Code,
+		Action:        models.AuditAwarded,
+		Source:        input.Source,
+		ActorID:       &actorID,
+		Reason:        input.Reason,
+		CreatedAt:     userAchievement.AwardedAt,
+	})
+	if err != nil {
+		log.Printf("Error auditing achievement %s: %v", achievement.Code, err)
+	}
+
 	// Get game name
 	var gameType models.GameType
 	err = db.GameTypeColl.FindOne(context.Background(), bson.M{"game_code": input.GameCode}).Decode(&gameType)
@@ -340,6 +432,7 @@ func AwardAchievement(c *gin.Context) {
 		IsHidden:    achievement.IsHidden,
 		IsUnlocked:  true,
 		AwardedAt:   &userAchievement.AwardedAt,
+		Points:      achievement.PointValue(),
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -352,11 +445,65 @@ func AwardAchievement(c *gin.Context) {
 	})
 }
 
+// RevokeAchievement removes one or all of a user's achievements, e.g. from a
+// caught cheater (admin only). Revoking one achievement also revokes the ones
+// that depend on it.
+func RevokeAchievement(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var input struct {
+		UserID        string `json:"userId" binding:"required"`
+		AchievementID string `json:"achievementId"` // Revokes every achievement when empty
+		Reason        string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(input.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var achievementID *primitive.ObjectID
+	if input.AchievementID != "" {
+		id, err := primitive.ObjectIDFromHex(input.AchievementID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid achievement ID"})
+			return
+		}
+		achievementID = &id
+	}
+
+	actorID, _ := auth.CurrentUserID(c)
+	revoked, err := services.RevokeAchievements(ctx, userID, achievementID, actorID, input.Reason)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke achievements"})
+		return
+	}
+	if achievementID != nil && len(revoked) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User does not have this achievement"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"message":             "Achievements revoked successfully",
+			"achievementsRevoked": len(revoked),
+			"revocations":         revoked,
+		},
+	})
+}
+
 // CheckAchievementProgress checks if a user has earned any achievements based on their game progress
 func CheckAchievementProgress(c *gin.Context) {
 	// Parse request body
 	var input struct {
-		UserID   string                 `json:"userId" binding:"required"`
+		UserID   string                 `json:"userId"`
 		GameCode string                 `json:"gameCode" binding:"required"`
 		Progress map[string]interface{} `json:"progress" binding:"required"`
 	}
@@ -366,434 +513,325 @@ func CheckAchievementProgress(c *gin.Context) {
 		return
 	}
 
-	// Convert user ID to ObjectID
-	userID, err := primitive.ObjectIDFromHex(input.UserID)
+	// Resolve the acting user from the access token
+	userID, ok := resolveClaimedUser(c, input.UserID)
+	if !ok {
+		return
+	}
+
+	// Score submissions evaluate achievements themselves, this covers other game events
+	achievements, err := services.EvaluateAchievements(context.Background(), userID, input.GameCode, input.Progress)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check achievements"})
 		return
 	}
 
-	// Get achievements for this game
-	var achievements []models.Achievement
-	filter := bson.M{
-		"$or": []bson.M{
-			{"game_code": input.GameCode},
-			{"game_code": "all"},
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"achievementsAwarded": len(achievements),
+			"achievements":        achievements,
 		},
+	})
+}
+
+// achievementRequest is the body accepted when creating or updating an achievement
+type achievementRequest struct {
+	GameCode      string           `json:"gameCode" binding:"required"`
+	Code          string           `json:"code" binding:"required"`
+	Title         string           `json:"title" binding:"required"`
+	Description   string           `json:"description"`
+	Icon          string           `json:"icon"`
+	Difficulty    string           `json:"difficulty" binding:"required"`
+	IsHidden      bool             `json:"isHidden"`
+	Criteria      *models.Criteria `json:"criteria"`
+	Points        int              `json:"points"`
+	TierGroup     string           `json:"tierGroup"`
+	Tier          int              `json:"tier"`
+	Prerequisites []string         `json:"prerequisites"`
+	SortOrder     *int             `json:"sortOrder"` // Kept as is when omitted on update
+}
+
+// apply copies the editable fields of the request onto an achievement
+func (r achievementRequest) apply(achievement *models.Achievement) {
+	achievement.Title = r.Title
+	achievement.Description = r.Description
+	achievement.Icon = r.Icon
+	achievement.Difficulty = r.Difficulty
+	achievement.IsHidden = r.IsHidden
+	achievement.Criteria = r.Criteria
+	achievement.Points = r.Points
+	achievement.TierGroup = r.TierGroup
+	achievement.Tier = r.Tier
+	achievement.Prerequisites = r.Prerequisites
+	if r.SortOrder != nil {
+		achievement.SortOrder = *r.SortOrder
 	}
+}
 
-	cursor, err := db.AchievementColl.Find(context.Background(), filter)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get achievements"})
+// CreateAchievement defines a new achievement (admin only)
+func CreateAchievement(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var input achievementRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	defer cursor.Close(context.Background())
 
-	if err := cursor.All(context.Background(), &achievements); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode achievements"})
+	now := time.Now()
+	achievement := models.Achievement{
+		GameCode:  input.GameCode,
+		Code:      input.Code,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	input.apply(&achievement)
+	if !validateAchievement(ctx, c, achievement) {
 		return
 	}
 
-	// Get user's existing achievements
-	var userAchievements []models.UserAchievement
-	userAchievementFilter := bson.M{"user_id": userID}
-	
-	userAchievementCursor, err := db.UserAchievementColl.Find(context.Background(), userAchievementFilter)
+	result, err := db.AchievementColl.InsertOne(ctx, achievement)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user achievements"})
+		if mongo.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "An achievement with this code already exists for the game"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create achievement"})
+		}
 		return
 	}
-	defer userAchievementCursor.Close(context.Background())
+	achievement.ID = result.InsertedID.(primitive.ObjectID)
 
-	if err := userAchievementCursor.All(context.Background(), &userAchievements); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode user achievements"})
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    achievement,
+	})
+}
+
+// UpdateAchievement changes an achievement (admin only). Its game and code are
+// fixed, since prerequisites and unlocked achievements refer to them.
+func UpdateAchievement(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	achievement, ok := findAchievement(ctx, c, c.Param("achievementId"))
+	if !ok {
 		return
 	}
 
-	// Create a map of existing achievements
-	existingAchievements := make(map[string]bool)
-	for _, ua := range userAchievements {
-		existingAchievements[ua.AchievementID.Hex()] = true
+	var input achievementRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.GameCode != achievement.GameCode || input.Code != achievement.Code {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The game and code of an achievement cannot change"})
+		return
 	}
 
-	// Check each achievement to see if it's been earned
-	var newlyAwardedAchievements []models.UserAchievement
-	var achievementsWithDetails []models.AchievementWithDetails
-
-	for _, achievement := range achievements {
-		// Skip if already earned
-		if existingAchievements[achievement.ID.Hex()] {
-			continue
-		}
+	input.apply(&achievement)
+	achievement.UpdatedAt = time.Now()
+	if !validateAchievement(ctx, c, achievement) {
+		return
+	}
 
-		// Check if achievement conditions are met based on game code and progress
-		if checkAchievementConditions(achievement, input.GameCode, input.Progress) {
-			// Award the achievement
-			userAchievement := models.UserAchievement{
-				UserID:        userID,
-				AchievementID: achievement.ID,
-				GameCode:      input.GameCode,
-				AwardedAt:     time.Now(),
-			}
+	_, err := db.AchievementColl.ReplaceOne(ctx, bson.M{"_id": achievement.ID}, achievement)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update achievement"})
+		return
+	}
 
-			// Insert user achievement
-			result, err := db.UserAchievementColl.InsertOne(context.Background(), userAchievement)
-			if err != nil {
-				log.Printf("Error awarding achievement %s: %v", achievement.Code, err)
-				continue
-			}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    achievement,
+	})
+}
 
-			// Get the inserted ID
-			userAchievement.ID = result.InsertedID.(primitive.ObjectID)
-			newlyAwardedAchievements = append(newlyAwardedAchievements, userAchievement)
+// RetireAchievement stops an achievement from being unlocked (admin only).
+// Users who already unlocked it keep it.
+func RetireAchievement(c *gin.Context) {
+	setAchievementRetired(c, true)
+}
 
-			// Get game name
-			var gameType models.GameType
-			err = db.GameTypeColl.FindOne(context.Background(), bson.M{"game_code": achievement.GameCode}).Decode(&gameType)
-			if err != nil && err != mongo.ErrNoDocuments {
-				log.Printf("Error finding game type: %v", err)
-			}
+// RestoreAchievement makes a retired achievement unlockable again (admin only)
+func RestoreAchievement(c *gin.Context) {
+	setAchievementRetired(c, false)
+}
 
-			gameName := achievement.GameCode
-			if err == nil {
-				gameName = gameType.Name
-			}
-			if achievement.GameCode == "all" {
-				gameName = "Global"
-			}
+// setAchievementRetired retires or restores the achievement in the URL
+func setAchievementRetired(c *gin.Context, retired bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-			// Create achievement with details for response
-			achievementWithDetails := models.AchievementWithDetails{
-				ID:          achievement.ID,
-				GameCode:    achievement.GameCode,
-				GameName:    gameName,
-				Code:        achievement.Code,
-				Title:       achievement.Title,
-				Description: achievement.Description,
-				Icon:        achievement.Icon,
-				Difficulty:  achievement.Difficulty,
-				IsHidden:    achievement.IsHidden,
-				IsUnlocked:  true,
-				AwardedAt:   &userAchievement.AwardedAt,
-			}
+	achievement, ok := findAchievement(ctx, c, c.Param("achievementId"))
+	if !ok {
+		return
+	}
 
-			achievementsWithDetails = append(achievementsWithDetails, achievementWithDetails)
-		}
+	achievement.Retired = retired
+	achievement.UpdatedAt = time.Now()
+	_, err := db.AchievementColl.UpdateOne(
+		ctx,
+		bson.M{"_id": achievement.ID},
+		bson.M{"$set": bson.M{"retired": retired, "updated_at": achievement.UpdatedAt}},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update achievement"})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data": gin.H{
-			"achievementsAwarded": len(newlyAwardedAchievements),
-			"achievements":        achievementsWithDetails,
-		},
+		"data":    achievement,
 	})
 }
 
-// checkAchievementConditions checks if the achievement conditions are met based on game progress
-func checkAchievementConditions(achievement models.Achievement, gameCode string, progress map[string]interface{}) bool {
-	// This is where you would implement the logic to check if the achievement conditions are met
-	// For now, we'll just return false since we don't have the actual game logic implemented
-	
-	// Example implementation:
-	switch achievement.GameCode {
-	case "colorguess":
-		return checkColorGuessAchievements(achievement, progress)
-	case "guess":
-		return checkGuessAchievements(achievement, progress)
-	case "hangman":
-		return checkHangmanAchievements(achievement, progress)
-	case "memorymatch":
-		return checkMemoryMatchAchievements(achievement, progress)
-	case "patternrepeater":
-		return checkPatternRepeaterAchievements(achievement, progress)
-	case "pong":
-		return checkPongAchievements(achievement, progress)
-	case "quickmath":
-		return checkQuickMathAchievements(achievement, progress)
-	case "simonsays":
-		return checkSimonSaysAchievements(achievement, progress)
-	case "typing":
-		return checkTypingAchievements(achievement, progress)
-	case "whackamole":
-		return checkWhackAMoleAchievements(achievement, progress)
-	case "all":
-		return checkGlobalAchievements(achievement, progress, gameCode)
-	}
-	
-	return false
-}
+// ReorderAchievements sets the listing order of achievements (admin only). The
+// achievements are listed in the order of the given IDs.
+func ReorderAchievements(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-// Game-specific achievement checks
-// These would be implemented with actual game logic
-func checkColorGuessAchievements(achievement models.Achievement, progress map[string]interface{}) bool {
-	// Example implementation
-	switch achievement.Code {
-	case "sharp_eyes":
-		if correct, ok := progress["correctAnswers"].(float64); ok && correct == 20 {
-			if mistakes, ok := progress["mistakes"].(float64); ok && mistakes == 0 {
-				return true
-			}
-		}
-	case "quick_colors":
-		if timeSpent, ok := progress["timeSpent"].(float64); ok && timeSpent < 30 {
-			return true
-		}
-	case "color_novice":
-		if completed, ok := progress["completed"].(bool); ok && completed {
-			return true
-		}
-	case "rainbow_master":
-		if playCount, ok := progress["playCount"].(float64); ok && playCount >= 50 {
-			return true
-		}
+	var input struct {
+		AchievementIDs []string `json:"achievementIds" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	return false
-}
 
-func checkGuessAchievements(achievement models.Achievement, progress map[string]interface{}) bool {
-	// Example implementation
-	switch achievement.Code {
-	case "lucky_guess":
-		if guessCount, ok := progress["guessCount"].(float64); ok && guessCount == 1 {
-			if correct, ok := progress["correct"].(bool); ok && correct {
-				return true
-			}
-		}
-	case "close_call":
-		if distance, ok := progress["distance"].(float64); ok && distance <= 2 {
-			return true
-		}
-	case "persistence":
-		if guessCount, ok := progress["guessCount"].(float64); ok && guessCount >= 10 {
-			return true
-		}
-	case "binary_search":
-		if guessCount, ok := progress["guessCount"].(float64); ok && guessCount <= 7 {
-			if correct, ok := progress["correct"].(bool); ok && correct {
-				return true
-			}
+	now := time.Now()
+	seen := make(map[primitive.ObjectID]bool, len(input.AchievementIDs))
+	updates := make([]mongo.WriteModel, 0, len(input.AchievementIDs))
+	for i, idStr := range input.AchievementIDs {
+		id, err := primitive.ObjectIDFromHex(idStr)
+		if err != nil || seen[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or duplicate achievement ID " + idStr})
+			return
 		}
+		seen[id] = true
+
+		updates = append(updates, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": id}).
+			SetUpdate(bson.M{"$set": bson.M{"sort_order": i + 1, "updated_at": now}}))
+	}
+	if len(updates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No achievements to reorder"})
+		return
 	}
-	return false
-}
 
-func checkHangmanAchievements(achievement models.Achievement, progress map[string]interface{}) bool {
-	// Example implementation
-	switch achievement.Code {
-	case "word_wizard":
-		if incorrectGuesses, ok := progress["incorrectGuesses"].(float64); ok && incorrectGuesses == 0 {
-			if solved, ok := progress["solved"].(bool); ok && solved {
-				return true
-			}
-		}
-	case "last_chance":
-		if remainingGuesses, ok := progress["remainingGuesses"].(float64); ok && remainingGuesses == 1 {
-			if solved, ok := progress["solved"].(bool); ok && solved {
-				return true
-			}
-		}
-	case "vowel_master":
-		if allVowelsGuessed, ok := progress["allVowelsGuessed"].(bool); ok && allVowelsGuessed {
-			return true
-		}
-	case "hangman_savior":
-		if winCount, ok := progress["winCount"].(float64); ok && winCount >= 10 {
-			return true
-		}
+	// Check every achievement exists before changing any of them
+	ids := make([]primitive.ObjectID, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	count, err := db.AchievementColl.CountDocuments(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find achievements"})
+		return
+	}
+	if int(count) != len(ids) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Achievement not found"})
+		return
 	}
-	return false
-}
 
-func checkMemoryMatchAchievements(achievement models.Achievement, progress map[string]interface{}) bool {
-	// Example implementation
-	switch achievement.Code {
-	case "photographic_memory":
-		if incorrectMatches, ok := progress["incorrectMatches"].(float64); ok && incorrectMatches == 0 {
-			if completed, ok := progress["completed"].(bool); ok && completed {
-				return true
-			}
-		}
-	case "speed_matcher":
-		if timeSpent, ok := progress["timeSpent"].(float64); ok && timeSpent < 30 {
-			if completed, ok := progress["completed"].(bool); ok && completed {
-				return true
-			}
-		}
-	case "memory_novice":
-		if completed, ok := progress["completed"].(bool); ok && completed {
-			return true
-		}
-	case "memory_streak":
-		if streak, ok := progress["streak"].(float64); ok && streak >= 5 {
-			return true
-		}
+	if _, err := db.AchievementColl.BulkWrite(ctx, updates); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder achievements"})
+		return
 	}
-	return false
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"message":   "Achievements reordered successfully",
+			"reordered": len(updates),
+		},
+	})
 }
 
-func checkPatternRepeaterAchievements(achievement models.Achievement, progress map[string]interface{}) bool {
-	// Example implementation
-	switch achievement.Code {
-	case "pattern_master":
-		if level, ok := progress["level"].(float64); ok && level >= 20 {
-			return true
-		}
-	case "rhythm_king":
-		if perfectTiming, ok := progress["perfectTiming"].(bool); ok && perfectTiming {
-			return true
-		}
-	case "pattern_novice":
-		if level, ok := progress["level"].(float64); ok && level >= 5 {
-			return true
-		}
-	case "pattern_addict":
-		if playCount, ok := progress["playCount"].(float64); ok && playCount >= 20 {
-			return true
-		}
-	}
-	return false
+// achievementOrder sorts achievements for listings
+func achievementOrder() *options.FindOptions {
+	return options.Find().SetSort(bson.D{{Key: "sort_order", Value: 1}, {Key: "_id", Value: 1}})
 }
 
-func checkPongAchievements(achievement models.Achievement, progress map[string]interface{}) bool {
-	// Example implementation
-	switch achievement.Code {
-	case "pong_champion":
-		if score, ok := progress["score"].(float64); ok && score >= 50 {
-			return true
-		}
-	case "pong_rally":
-		if rallyLength, ok := progress["rallyLength"].(float64); ok && rallyLength >= 20 {
-			return true
-		}
-	case "pong_novice":
-		if score, ok := progress["score"].(float64); ok && score >= 1 {
-			return true
-		}
-	case "pong_comeback":
-		if comeback, ok := progress["comeback"].(bool); ok && comeback {
-			if deficitOvercome, ok := progress["deficitOvercome"].(float64); ok && deficitOvercome >= 5 {
-				return true
-			}
-		}
+// findAchievement loads an achievement by its hex ID, responding with an error if it cannot
+func findAchievement(ctx context.Context, c *gin.Context, achievementID string) (models.Achievement, bool) {
+	id, err := primitive.ObjectIDFromHex(achievementID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid achievement ID"})
+		return models.Achievement{}, false
 	}
-	return false
-}
 
-func checkQuickMathAchievements(achievement models.Achievement, progress map[string]interface{}) bool {
-	// Example implementation
-	switch achievement.Code {
-	case "math_genius":
-		if streak, ok := progress["streak"].(float64); ok && streak >= 15 {
-			return true
-		}
-	case "speed_calculator":
-		if questionsAnswered, ok := progress["questionsAnswered"].(float64); ok && questionsAnswered >= 10 {
-			if timeSpent, ok := progress["timeSpent"].(float64); ok && timeSpent < 20 {
-				return true
-			}
-		}
-	case "math_novice":
-		if completed, ok := progress["completed"].(bool); ok && completed {
-			return true
-		}
-	case "math_addict":
-		if playCount, ok := progress["playCount"].(float64); ok && playCount >= 30 {
-			return true
+	var achievement models.Achievement
+	err = db.AchievementColl.FindOne(ctx, bson.M{"_id": id}).Decode(&achievement)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Achievement not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find achievement"})
 		}
+		return models.Achievement{}, false
 	}
-	return false
+	return achievement, true
 }
 
-func checkSimonSaysAchievements(achievement models.Achievement, progress map[string]interface{}) bool {
-	// Example implementation
-	switch achievement.Code {
-	case "simon_master":
-		if level, ok := progress["level"].(float64); ok && level >= 15 {
-			return true
-		}
-	case "simon_streak":
-		if streak, ok := progress["streak"].(float64); ok && streak >= 5 {
-			return true
+// validateAchievement checks an achievement's game, difficulty, rule, tier and
+// prerequisites, responding with an error if it is not valid
+func validateAchievement(ctx context.Context, c *gin.Context, achievement models.Achievement) bool {
+	if achievement.GameCode != "all" {
+		count, err := db.GameTypeColl.CountDocuments(ctx, bson.M{"game_code": achievement.GameCode})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find game type"})
+			return false
 		}
-	case "simon_novice":
-		if level, ok := progress["level"].(float64); ok && level >= 5 {
-			return true
-		}
-	case "simon_says_dance":
-		if perfectTiming, ok := progress["perfectTiming"].(bool); ok && perfectTiming {
-			return true
+		if count == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "gameCode must be a game type or all"})
+			return false
 		}
 	}
-	return false
-}
 
-func checkTypingAchievements(achievement models.Achievement, progress map[string]interface{}) bool {
-	// Example implementation
-	switch achievement.Code {
-	case "typing_master":
-		if wpm, ok := progress["wpm"].(float64); ok && wpm >= 80 {
-			return true
-		}
-	case "perfect_typist":
-		if errors, ok := progress["errors"].(float64); ok && errors == 0 {
-			if completed, ok := progress["completed"].(bool); ok && completed {
-				return true
-			}
-		}
-	case "typing_novice":
-		if completed, ok := progress["completed"].(bool); ok && completed {
-			return true
-		}
-	case "typing_marathon":
-		if totalWords, ok := progress["totalWords"].(float64); ok && totalWords >= 1000 {
-			return true
-		}
+	if _, ok := models.DifficultyPoints[achievement.Difficulty]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "difficulty must be easy, medium or hard"})
+		return false
+	}
+	if achievement.Points < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "points cannot be negative"})
+		return false
+	}
+	if err := services.ValidateCriteria(achievement.Criteria); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
 	}
-	return false
-}
 
-func checkWhackAMoleAchievements(achievement models.Achievement, progress map[string]interface{}) bool {
-	// Example implementation
-	switch achievement.Code {
-	case "mole_hunter":
-		if molesWhacked, ok := progress["molesWhacked"].(float64); ok && molesWhacked >= 50 {
-			return true
-		}
-	case "quick_reflexes":
-		if streak, ok := progress["streak"].(float64); ok && streak >= 10 {
-			return true
-		}
-	case "mole_novice":
-		if molesWhacked, ok := progress["molesWhacked"].(float64); ok && molesWhacked >= 1 {
-			return true
-		}
-	case "mole_frenzy":
-		if frenzy, ok := progress["frenzy"].(bool); ok && frenzy {
-			return true
-		}
+	// A tiered achievement needs both its group and a known tier
+	if (achievement.TierGroup == "") != (achievement.Tier == 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tierGroup and tier must be set together"})
+		return false
+	}
+	if _, ok := models.TierNames[achievement.Tier]; achievement.Tier != 0 && !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tier must be 1 (bronze), 2 (silver) or 3 (gold)"})
+		return false
 	}
-	return false
-}
 
-func checkGlobalAchievements(achievement models.Achievement, progress map[string]interface{}, gameCode string) bool {
-	// Example implementation
-	switch achievement.Code {
-	case "night_owl":
-		// Check if current time is between 12 AM and 4 AM
-		currentHour := time.Now().Hour()
-		if currentHour >= 0 && currentHour < 4 {
-			return true
+	// Prerequisites are other achievements of the same game
+	if len(achievement.Prerequisites) > 0 {
+		if slices.Contains(achievement.Prerequisites, achievement.Code) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "An achievement cannot be its own prerequisite"})
+			return false
 		}
-	case "game_hopper":
-		if gamesPlayed, ok := progress["gamesPlayed"].(map[string]interface{}); ok {
-			// Check if all 10 games have been played
-			return len(gamesPlayed) >= 10
+		count, err := db.AchievementColl.CountDocuments(ctx, bson.M{
+			"game_code": achievement.GameCode,
+			"code":      bson.M{"$in": achievement.Prerequisites},
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find prerequisites"})
+			return false
 		}
-	case "achievement_hunter":
-		if achievementCount, ok := progress["achievementCount"].(float64); ok && achievementCount >= 20 {
-			return true
+		if int(count) != len(achievement.Prerequisites) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown or duplicate achievement code in prerequisites"})
+			return false
 		}
 	}
-	return false
+	return true
 }
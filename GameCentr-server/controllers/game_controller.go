@@ -2,9 +2,12 @@ package controllers
 
 import (
 	"context"
+	"math"
 	"net/http"
 	"netgames-go-server/db"
 	"netgames-go-server/models"
+	"netgames-go-server/services"
+	"regexp"
 	"strconv"
 	"time"
 
@@ -24,7 +27,13 @@ func GetAllGameTypes(c *gin.Context) {
 	findOptions := options.Find()
 	findOptions.SetSort(bson.D{{Key: "name", Value: 1}})
 
-	cursor, err := db.GameTypeColl.Find(ctx, bson.M{}, findOptions)
+	// Disabled games are only listed on request
+	filter := bson.M{}
+	if c.Query("includeDisabled") != "true" {
+		filter["disabled"] = bson.M{"$ne": true}
+	}
+
+	cursor, err := db.GameTypeColl.Find(ctx, filter, findOptions)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -81,6 +90,309 @@ func GetGameTypeByCode(c *gin.Context) {
 	})
 }
 
+// gameTypeRequest is the body accepted when creating or updating a game type.
+// Fields left out of an update keep their current value.
+type gameTypeRequest struct {
+	GameCode      string  `json:"game_code"`
+	Name          *string `json:"name"`
+	Description   *string `json:"description"`
+	ScoringType   *string `json:"scoring_type"`
+	MaxScore      *int    `json:"max_score"` // 0 removes the maximum
+	SortDirection *string `json:"sort_direction"`
+	MinDuration   *int    `json:"min_duration"`
+}
+
+// gameCodePattern restricts game codes to what can safely appear in URLs
+var gameCodePattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// CreateGameType adds a new game (admin only)
+func CreateGameType(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var request gameTypeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if request.Name == nil || request.ScoringType == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "name and scoring_type are required",
+		})
+		return
+	}
+	if !gameCodePattern.MatchString(request.GameCode) || request.GameCode == "all" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "game_code must consist of lowercase letters, digits, - and _ and cannot be all",
+		})
+		return
+	}
+
+	now := time.Now()
+	gameType := models.GameType{
+		GameCode:      request.GameCode,
+		SortDirection: models.SortDescending,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if !applyGameTypeRequest(c, request, &gameType) {
+		return
+	}
+
+	result, err := db.GameTypeColl.InsertOne(ctx, gameType)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{
+				"success": false,
+				"message": "A game type with this code already exists",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	gameType.ID = result.InsertedID.(primitive.ObjectID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Successfully created game type",
+		"data":    gameType,
+	})
+}
+
+// UpdateGameType changes a game's name, description and scoring rules (admin
+// only). Its code is fixed, since scores refer to it.
+func UpdateGameType(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	gameType, ok := findGameType(ctx, c, c.Param("gameCode"))
+	if !ok {
+		return
+	}
+
+	var request gameTypeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	if request.GameCode != "" && request.GameCode != gameType.GameCode {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "The code of a game type cannot change",
+		})
+		return
+	}
+
+	previousDirection := gameType.SortDirection
+	gameType.UpdatedAt = time.Now()
+	if !applyGameTypeRequest(c, request, &gameType) {
+		return
+	}
+
+	_, err := db.GameTypeColl.ReplaceOne(ctx, bson.M{"_id": gameType.ID}, gameType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// Re-rank the stored leaderboards when the best scores are now at the other end
+	if gameType.SortDirection != previousDirection {
+		services.RefreshGameLeaderboardsAsync(gameType)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Successfully updated game type",
+		"data":    gameType,
+	})
+}
+
+// DisableGameType hides a game and stops it from accepting new scores (admin
+// only). Its existing scores and leaderboards are kept.
+func DisableGameType(c *gin.Context) {
+	setGameTypeDisabled(c, true)
+}
+
+// EnableGameType makes a disabled game available again (admin only)
+func EnableGameType(c *gin.Context) {
+	setGameTypeDisabled(c, false)
+}
+
+// setGameTypeDisabled disables or enables the game in the URL
+func setGameTypeDisabled(c *gin.Context, disabled bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var gameType models.GameType
+	err := db.GameTypeColl.FindOneAndUpdate(
+		ctx,
+		bson.M{"game_code": c.Param("gameCode")},
+		bson.M{"$set": bson.M{"disabled": disabled, "updatedAt": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&gameType)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"message": "Game type not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	message := "Successfully enabled game type"
+	if disabled {
+		message = "Successfully disabled game type"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": message,
+		"data":    gameType,
+	})
+}
+
+// applyGameTypeRequest copies the fields set in the request onto the game type
+// and validates the result, responding with an error if it is not valid
+func applyGameTypeRequest(c *gin.Context, request gameTypeRequest, gameType *models.GameType) bool {
+	updated := *gameType
+	if request.Name != nil {
+		updated.Name = *request.Name
+	}
+	if request.Description != nil {
+		updated.Description = *request.Description
+	}
+	if request.ScoringType != nil {
+		updated.ScoringType = *request.ScoringType
+	}
+	if request.MaxScore != nil {
+		updated.MaxScore = request.MaxScore
+		if *request.MaxScore == 0 {
+			updated.MaxScore = nil
+		}
+	}
+	if request.SortDirection != nil {
+		updated.SortDirection = *request.SortDirection
+	}
+	if request.MinDuration != nil {
+		updated.MinDuration = *request.MinDuration
+	}
+
+	var message string
+	switch {
+	case updated.Name == "":
+		message = "name cannot be empty"
+	case !models.IsValidScoringType(updated.ScoringType):
+		message = "Unknown scoring_type"
+	case !models.IsValidSortDirection(updated.SortDirection):
+		message = "sort_direction must be asc or desc"
+	case updated.MaxScore != nil && *updated.MaxScore < 0:
+		message = "max_score cannot be negative"
+	case updated.MinDuration < 0:
+		message = "min_duration cannot be negative"
+	}
+	if message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": message,
+		})
+		return false
+	}
+
+	*gameType = updated
+	return true
+}
+
+// findGameType loads a game type by its code, responding with an error if it cannot
+func findGameType(ctx context.Context, c *gin.Context, gameCode string) (models.GameType, bool) {
+	var gameType models.GameType
+	err := db.GameTypeColl.FindOne(ctx, bson.M{"game_code": gameCode}).Decode(&gameType)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"message": "Game type not found",
+			})
+			return models.GameType{}, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return models.GameType{}, false
+	}
+	return gameType, true
+}
+
+// GetGameMetadataSchema returns the JSON Schema for the metadata a game accepts with its scores
+func GetGameMetadataSchema(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	gameCode := c.Param("gameCode")
+
+	var gameType models.GameType
+	err := db.GameTypeColl.FindOne(ctx, bson.M{"game_code": gameCode}).Decode(&gameType)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"message": "Game type not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// Games without a schema accept any metadata object
+	schema := gameType.MetadataSchema
+	if schema == nil {
+		schema = &models.MetadataSchema{
+			Type:                 models.SchemaObject,
+			Properties:           map[string]models.MetadataProperty{},
+			AdditionalProperties: true,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Successfully retrieved metadata schema",
+		"data": struct {
+			Schema string `json:"$schema"`
+			Title  string `json:"title"`
+			*models.MetadataSchema
+		}{
+			Schema:         "https://json-schema.org/draft/2020-12/schema",
+			Title:          gameType.Name + " score metadata",
+			MetadataSchema: schema,
+		},
+	})
+}
+
 // GetGameLeaderboard retrieves top scores for a specific game with filtering options
 func GetGameLeaderboard(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -95,28 +407,19 @@ func GetGameLeaderboard(c *gin.Context) {
 		limit = 10 // Default limit
 	}
 	
-	timeFrame := c.DefaultQuery("timeFrame", "all") // all, daily, weekly, monthly
-	
-	// Build the filter
-	filter := bson.M{"game": gameCode}
-	
-	// Add time frame filter if specified
-	if timeFrame != "all" {
-		var startTime time.Time
-		now := time.Now()
-		
-		switch timeFrame {
-		case "daily":
-			startTime = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-		case "weekly":
-			startTime = now.AddDate(0, 0, -7)
-		case "monthly":
-			startTime = now.AddDate(0, -1, 0)
-		}
-		
-		if !startTime.IsZero() {
-			filter["createdAt"] = bson.M{"$gte": startTime}
-		}
+	timeFrame, ok := parseTimeFrame(ctx, c)
+	if !ok {
+		return
+	}
+
+	// best ranks each player's best score once, all ranks every run
+	mode := c.DefaultQuery("mode", "best")
+	if mode != "best" && mode != "all" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid mode, expected best or all",
+		})
+		return
 	}
 	
 	// Get game type to determine scoring type
@@ -130,87 +433,310 @@ func GetGameLeaderboard(c *gin.Context) {
 		return
 	}
 	
-	// Set options to sort by score in descending order and limit results
-	findOptions := options.Find()
-	findOptions.SetSort(bson.D{{Key: "value", Value: -1}})
-	findOptions.SetLimit(int64(limit))
+	// Serve each player's best from the materialized leaderboard when it covers
+	// the request, and fall back to ranking the scores directly otherwise
+	var entries []models.LeaderboardEntry
+	if mode == "best" && limit <= services.MaterializedLeaderboardSize && timeFrame.Materialized() {
+		leaderboard, err := services.GetMaterializedLeaderboard(ctx, gameCode, timeFrame)
+		if err == nil && leaderboard == nil {
+			var refreshed models.Leaderboard
+			refreshed, err = services.RefreshLeaderboard(ctx, gameType, timeFrame.Name)
+			leaderboard = &refreshed
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": err.Error(),
+			})
+			return
+		}
+		entries = leaderboard.Entries
+		if len(entries) > limit {
+			entries = entries[:limit]
+		}
+	} else {
+		filter := services.ScoreFilter(gameCode, timeFrame)
+		entries, err = services.TopEntries(ctx, filter, gameType, limit, mode == "best")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": err.Error(),
+			})
+			return
+		}
+	}
 
-	cursor, err := db.ScoreColl.Find(ctx, filter, findOptions)
+	leaderboardEntries := leaderboardEntriesResponse(entries, gameType)
+
+	// Get aggregated stats
+	aggregatedStats, err := getGameAggregatedStats(ctx, gameType)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Successfully retrieved leaderboard, but failed to get aggregated stats",
+			"data":    leaderboardEntries,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message":   "Successfully retrieved leaderboard",
+		"mode":      mode,
+		"timeFrame": timeFrame.Name,
+		"timeZone":  timeFrame.Location.String(),
+		"data":      leaderboardEntries,
+		"stats":     aggregatedStats,
+		"gameInfo": gin.H{
+			"name":          gameType.Name,
+			"description":   gameType.Description,
+			"scoringType":   gameType.ScoringType,
+			"maxScore":      gameType.MaxScore,
+			"sortDirection": gameType.SortDirection,
+		},
+	})
+}
+
+// GetUserLeaderboardPosition retrieves a player's rank on a game leaderboard
+// together with the players ranked directly above and below them
+func GetUserLeaderboardPosition(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	gameCode := c.Param("gameCode")
+
+	userObjectId, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
-			"message": err.Error(),
+			"message": "Invalid user ID",
 		})
 		return
 	}
-	defer cursor.Close(ctx)
 
-	var scores []models.Score
-	if err := cursor.All(ctx, &scores); err != nil {
+	// Number of neighbours to return on each side
+	around, err := strconv.Atoi(c.DefaultQuery("range", "5"))
+	if err != nil || around < 0 {
+		around = 5
+	}
+	if around > 25 {
+		around = 25
+	}
+
+	timeFrame, ok := parseTimeFrame(ctx, c)
+	if !ok {
+		return
+	}
+
+	var gameType models.GameType
+	err = db.GameTypeColl.FindOne(ctx, bson.M{"game_code": gameCode}).Decode(&gameType)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"message": "Game type not found",
+		})
+		return
+	}
+
+	var user models.User
+	err = db.UserColl.FindOne(ctx, bson.M{"_id": userObjectId}).Decode(&user)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"message": "User not found",
+		})
+		return
+	}
+
+	filter := services.ScoreFilter(gameCode, timeFrame)
+	standing, err := services.GetPlayerStanding(ctx, filter, gameType, userObjectId, around)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"message": err.Error(),
 		})
 		return
 	}
+	if standing == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"message": "User has no scores for this game in this timeframe",
+		})
+		return
+	}
 
-	var leaderboardEntries []gin.H
-	for i, score := range scores {
-		// Populate owner
-		var owner models.User
-		err := db.UserColl.FindOne(ctx, bson.M{"_id": score.Owner}).Decode(&owner)
-		if err != nil {
-			continue // Skip if owner not found
-		}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Successfully retrieved leaderboard position",
+		"data": gin.H{
+			"user":         user.ToResponse(),
+			"rank":         standing.Entry.Rank,
+			"totalPlayers": standing.TotalPlayers,
+			"percentile":   standing.Percentile,
+			"bestScore":    standing.Entry.Score,
+			"createdAt":    standing.Entry.CreatedAt,
+			"above":        leaderboardEntriesResponse(standing.Above, gameType),
+			"below":        leaderboardEntriesResponse(standing.Below, gameType),
+			"timeFrame":    timeFrame.Name,
+			"timeZone":     timeFrame.Location.String(),
+		},
+	})
+}
 
-		leaderboardEntries = append(leaderboardEntries, gin.H{
-			"rank":       i + 1,
-			"score":      score.Value,
-			"user":       owner.ToResponse(),
-			"metadata":   score.Metadata,
-			"createdAt":  score.CreatedAt,
-			"scoringType": gameType.ScoringType,
+// GetGameRatingLeaderboard retrieves the highest rated players of a game
+func GetGameRatingLeaderboard(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	gameCode := c.Param("gameCode")
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10 // Default limit
+	}
+
+	var gameType models.GameType
+	err = db.GameTypeColl.FindOne(ctx, bson.M{"game_code": gameCode}).Decode(&gameType)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"message": "Game type not found",
 		})
+		return
 	}
 
-	// Get aggregated stats
-	aggregatedStats, err := getGameAggregatedStats(ctx, gameCode)
+	ratings, err := services.TopRatings(ctx, gameCode, limit)
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"success": true,
-			"message": "Successfully retrieved leaderboard, but failed to get aggregated stats",
-			"data":    leaderboardEntries,
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
 		})
 		return
 	}
 
+	// Populate the players in a single query
+	userIds := make([]primitive.ObjectID, 0, len(ratings))
+	for _, rating := range ratings {
+		userIds = append(userIds, rating.UserID)
+	}
+	cursor, err := db.UserColl.Find(ctx, bson.M{"_id": bson.M{"$in": userIds}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err = cursor.All(ctx, &users); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	usersById := make(map[primitive.ObjectID]models.User, len(users))
+	for _, user := range users {
+		usersById[user.ID] = user
+	}
+
+	now := time.Now()
+	leaderboard := []gin.H{}
+	for _, rating := range ratings {
+		user, ok := usersById[rating.UserID]
+		if !ok {
+			continue // Skip if user not found
+		}
+
+		entry := ratingResponse(rating, now)
+		entry["rank"] = len(leaderboard) + 1
+		entry["user"] = gin.H{
+			"_id":      user.ID,
+			"username": user.Username,
+		}
+		leaderboard = append(leaderboard, entry)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Successfully retrieved leaderboard",
-		"data":    leaderboardEntries,
-		"stats":   aggregatedStats,
+		"message": "Successfully retrieved rating leaderboard",
+		"data":    leaderboard,
 		"gameInfo": gin.H{
 			"name":        gameType.Name,
 			"description": gameType.Description,
-			"scoringType": gameType.ScoringType,
-			"maxScore":    gameType.MaxScore,
 		},
 	})
 }
 
-// getGameAggregatedStats retrieves aggregated statistics for a game
-func getGameAggregatedStats(ctx context.Context, gameCode string) (gin.H, error) {
+// ratingResponse describes a skill rating as of now
+func ratingResponse(rating models.Rating, now time.Time) gin.H {
+	return gin.H{
+		"rating":             math.Round(rating.Rating),
+		"deviation":          math.Round(services.CurrentDeviation(rating, now)),
+		"conservativeRating": math.Round(rating.Conservative),
+		"plays":              rating.Plays,
+		"provisional":        services.IsProvisional(rating, now),
+	}
+}
+
+// leaderboardEntriesResponse formats leaderboard entries the way the client renders them
+func leaderboardEntriesResponse(entries []models.LeaderboardEntry, gameType models.GameType) []gin.H {
+	response := []gin.H{}
+	for _, entry := range entries {
+		response = append(response, gin.H{
+			"rank":  entry.Rank,
+			"score": entry.Score,
+			"user": gin.H{
+				"_id":      entry.UserID,
+				"username": entry.Username,
+			},
+			"metadata":    entry.Metadata,
+			"createdAt":   entry.CreatedAt,
+			"scoringType": gameType.ScoringType,
+		})
+	}
+	return response
+}
+
+// parseTimeFrame reads the timeFrame and tz query parameters, or the season
+// query parameter for season leaderboards, responding with an error if they
+// are invalid
+func parseTimeFrame(ctx context.Context, c *gin.Context) (services.TimeFrame, bool) {
+	if seasonId := c.Query("season"); seasonId != "" {
+		season, ok := findSeason(ctx, c, seasonId)
+		if !ok {
+			return services.TimeFrame{}, false
+		}
+		return services.SeasonTimeFrame(season), true
+	}
+
+	timeFrame, err := services.ParseTimeFrame(c.DefaultQuery("timeFrame", services.TimeFrameAll), c.Query("tz"), time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return services.TimeFrame{}, false
+	}
+	return timeFrame, true
+}
+
+// getGameAggregatedStats retrieves aggregated statistics for a game.
+// highestScore is the best score and lowestScore the worst, following the game's sort direction.
+func getGameAggregatedStats(ctx context.Context, gameType models.GameType) (gin.H, error) {
 	// Pipeline for aggregating game stats
 	pipeline := mongo.Pipeline{
 		// Match documents for this game
-		{{"$match", bson.M{"game": gameCode}}},
+		{{Key: "$match", Value: bson.M{"game": gameType.GameCode}}},
 		// Group and calculate statistics
-		{{"$group", bson.M{
+		{{Key: "$group", Value: bson.M{
 			"_id":          nil,
 			"totalPlays":   bson.M{"$sum": 1},
 			"averageScore": bson.M{"$avg": "$value"},
-			"highestScore": bson.M{"$max": "$value"},
-			"lowestScore":  bson.M{"$min": "$value"},
+			"maxScore":     bson.M{"$max": "$value"},
+			"minScore":     bson.M{"$min": "$value"},
 		}}},
 	}
 
@@ -235,11 +761,16 @@ func getGameAggregatedStats(ctx context.Context, gameCode string) (gin.H, error)
 		}, nil
 	}
 
+	best, worst := results[0]["maxScore"], results[0]["minScore"]
+	if gameType.LowerIsBetter() {
+		best, worst = worst, best
+	}
+
 	return gin.H{
 		"totalPlays":   results[0]["totalPlays"],
 		"averageScore": results[0]["averageScore"],
-		"highestScore": results[0]["highestScore"],
-		"lowestScore":  results[0]["lowestScore"],
+		"highestScore": best,
+		"lowestScore":  worst,
 	}, nil
 }
 
@@ -273,13 +804,12 @@ func GetUserGameStats(c *gin.Context) {
 	// Pipeline for aggregating user stats across all games
 	overallPipeline := mongo.Pipeline{
 		// Match documents for this user
-		{{"$match", bson.M{"owner": userObjectId}}},
+		{{Key: "$match", Value: bson.M{"owner": userObjectId}}},
 		// Group and calculate statistics
-		{{"$group", bson.M{
+		{{Key: "$group", Value: bson.M{
 			"_id":          nil,
 			"totalPlays":   bson.M{"$sum": 1},
 			"averageScore": bson.M{"$avg": "$value"},
-			"highestScore": bson.M{"$max": "$value"},
 		}}},
 	}
 
@@ -305,17 +835,18 @@ func GetUserGameStats(c *gin.Context) {
 	// Pipeline for aggregating user stats by game
 	byGamePipeline := mongo.Pipeline{
 		// Match documents for this user
-		{{"$match", bson.M{"owner": userObjectId}}},
+		{{Key: "$match", Value: bson.M{"owner": userObjectId}}},
 		// Group by game and calculate statistics
-		{{"$group", bson.M{
+		{{Key: "$group", Value: bson.M{
 			"_id":          "$game",
 			"totalPlays":   bson.M{"$sum": 1},
 			"averageScore": bson.M{"$avg": "$value"},
-			"highestScore": bson.M{"$max": "$value"},
+			"maxScore":     bson.M{"$max": "$value"},
+			"minScore":     bson.M{"$min": "$value"},
 			"lastPlayed":   bson.M{"$max": "$createdAt"},
 		}}},
 		// Sort by most played
-		{{"$sort", bson.M{"totalPlays": -1}}},
+		{{Key: "$sort", Value: bson.M{"totalPlays": -1}}},
 	}
 
 	byGameCursor, err := db.ScoreColl.Aggregate(ctx, byGamePipeline)
@@ -337,8 +868,21 @@ func GetUserGameStats(c *gin.Context) {
 		return
 	}
 
-	// Enhance game stats with game info
+	// Get the user's skill rating in each game
+	ratings, err := services.GetUserRatings(ctx, userObjectId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// Enhance game stats with game info. The highest score of each game is its
+	// personal best, which is the lowest value for lower-is-better games.
+	now := time.Now()
 	var gameStats []gin.H
+	var overallHighest interface{}
 	for _, stat := range byGameResults {
 		gameCode := stat["_id"].(string)
 		
@@ -350,24 +894,44 @@ func GetUserGameStats(c *gin.Context) {
 			continue
 		}
 		
+		bestScore := stat["maxScore"]
+		if gameType.LowerIsBetter() {
+			bestScore = stat["minScore"]
+		} else if overallHighest == nil || compareNumbers(bestScore, overallHighest) > 0 {
+			// Only higher-is-better games can be compared for the overall highest score
+			overallHighest = bestScore
+		}
+
+		// Players whose rating was not computed yet start from the default rating
+		rating, ok := ratings[gameCode]
+		if !ok {
+			rating = services.NewRating(userObjectId, gameCode)
+		}
+
 		gameStats = append(gameStats, gin.H{
-			"gameCode":     gameCode,
-			"gameName":     gameType.Name,
-			"totalPlays":   stat["totalPlays"],
-			"averageScore": stat["averageScore"],
-			"highestScore": stat["highestScore"],
-			"lastPlayed":   stat["lastPlayed"],
-			"scoringType":  gameType.ScoringType,
+			"gameCode":      gameCode,
+			"gameName":      gameType.Name,
+			"totalPlays":    stat["totalPlays"],
+			"averageScore":  stat["averageScore"],
+			"highestScore":  bestScore,
+			"lastPlayed":    stat["lastPlayed"],
+			"scoringType":   gameType.ScoringType,
+			"sortDirection": gameType.SortDirection,
+			"rating":        ratingResponse(rating, now),
 		})
 	}
 
+	if overallHighest == nil {
+		overallHighest = 0
+	}
+
 	// Prepare response
 	var overallStats gin.H
 	if len(overallResults) > 0 {
 		overallStats = gin.H{
 			"totalPlays":   overallResults[0]["totalPlays"],
 			"averageScore": overallResults[0]["averageScore"],
-			"highestScore": overallResults[0]["highestScore"],
+			"highestScore": overallHighest,
 			"totalGames":   len(gameStats),
 		}
 	} else {
@@ -400,70 +964,46 @@ func GetGlobalLeaderboard(c *gin.Context) {
 		limit = 10 // Default limit
 	}
 
-	// Pipeline for aggregating top players
-	pipeline := mongo.Pipeline{
-		// Group by user and calculate statistics
-		{{"$group", bson.M{
-			"_id":        "$owner",
-			"totalScore": bson.M{"$sum": "$value"},
-			"totalPlays": bson.M{"$sum": 1},
-			"avgScore":   bson.M{"$avg": "$value"},
-			"games":      bson.M{"$addToSet": "$game"},
-		}}},
-		// Sort by total score descending
-		{{"$sort", bson.M{"totalScore": -1}}},
-		// Limit results
-		{{"$limit", int64(limit)}},
-	}
+	// Games are normalized before they are combined, see services.GlobalMethodDescriptions
+	method := c.DefaultQuery("method", services.GlobalMethodPercentile)
 
-	cursor, err := db.ScoreColl.Aggregate(ctx, pipeline)
+	standings, err := services.GetGlobalLeaderboard(ctx, method, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": err.Error(),
-		})
-		return
-	}
-	defer cursor.Close(ctx)
-
-	var results []bson.M
-	if err = cursor.All(ctx, &results); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		status := http.StatusInternalServerError
+		if err == services.ErrInvalidGlobalMethod {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
 			"success": false,
 			"message": err.Error(),
 		})
 		return
 	}
 
-	// Enhance with user details
-	var leaderboard []gin.H
-	for i, result := range results {
-		userId := result["_id"].(primitive.ObjectID)
-		
-		// Get user details
-		var user models.User
-		err = db.UserColl.FindOne(ctx, bson.M{"_id": userId}).Decode(&user)
-		if err != nil {
-			continue // Skip if user not found
-		}
-		
-		// Count unique games played
-		gamesPlayed := len(result["games"].(primitive.A))
-		
+	leaderboard := []gin.H{}
+	for _, standing := range standings {
 		leaderboard = append(leaderboard, gin.H{
-			"rank":        i + 1,
-			"user":        user.ToResponse(),
-			"totalScore":  result["totalScore"],
-			"totalPlays":  result["totalPlays"],
-			"avgScore":    result["avgScore"],
-			"gamesPlayed": gamesPlayed,
+			"rank": standing.Rank,
+			"user": gin.H{
+				"_id":      standing.UserID,
+				"username": standing.Username,
+			},
+			"totalScore":  standing.Total,
+			"totalPlays":  standing.TotalPlays,
+			"avgScore":    standing.Average,
+			"gamesPlayed": len(standing.GamePoints),
+			"games":       standing.GamePoints,
 		})
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Successfully retrieved global leaderboard",
-		"data":    leaderboard,
+		"method": gin.H{
+			"name":        method,
+			"description": services.GlobalMethodDescriptions[method],
+		},
+		"data": leaderboard,
 	})
 }
 
@@ -516,3 +1056,30 @@ func GetUserGameScores(c *gin.Context) {
 		"data":    scores,
 	})
 }
+
+// compareNumbers compares two numeric values decoded from an aggregation result
+func compareNumbers(a, b interface{}) int {
+	x, y := toFloat64(a), toFloat64(b)
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// toFloat64 converts a numeric BSON value to float64
+func toFloat64(value interface{}) float64 {
+	switch v := value.(type) {
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case int:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"log"
+	"net/http"
+	"netgames-go-server/auth"
+	"netgames-go-server/db"
+	"netgames-go-server/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// gameSessionTTL is the longest a single play may take before its session expires
+const gameSessionTTL = 2 * time.Hour
+
+var (
+	errGameSessionRequired = errors.New("a game session is required to submit a score")
+	errGameSessionInvalid  = errors.New("invalid game session")
+	errGameSessionExpired  = errors.New("game session has expired")
+	errGameSessionUsed     = errors.New("game session has already been used")
+	errGameSessionTooShort = errors.New("game session is too short for this game")
+)
+
+// StartGameSession issues a signed session that a score for the game must be submitted against
+func StartGameSession(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userID, ok := resolveActingUser(c, primitive.NilObjectID)
+	if !ok {
+		return
+	}
+
+	gameCode := c.Param("gameCode")
+
+	// Make sure the game exists
+	var gameType models.GameType
+	err := db.GameTypeColl.FindOne(ctx, bson.M{"game_code": gameCode}).Decode(&gameType)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"message": "Game type not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if gameType.Disabled {
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"message": "Game is disabled",
+		})
+		return
+	}
+
+	seed, err := newGameSeed()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Error generating game seed",
+		})
+		return
+	}
+
+	// MongoDB stores times with millisecond precision
+	now := time.Now().Truncate(time.Millisecond)
+	session := models.GameSession{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		GameCode:  gameType.GameCode,
+		Seed:      seed,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(gameSessionTTL),
+	}
+
+	if _, err := db.GameSessionColl.InsertOne(ctx, session); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Successfully started game session",
+		"data": gin.H{
+			"session":    auth.SignGameSession(session),
+			"sessionId":  session.ID,
+			"gameCode":   session.GameCode,
+			"seed":       session.Seed,
+			"serverTime": session.IssuedAt,
+			"expiresAt":  session.ExpiresAt,
+		},
+	})
+}
+
+// claimGameSession verifies a session token and marks the session as used so
+// it can never back a second score
+func claimGameSession(ctx context.Context, token string, userID primitive.ObjectID, gameType models.GameType) (models.GameSession, error) {
+	if token == "" {
+		return models.GameSession{}, errGameSessionRequired
+	}
+
+	sessionID, err := auth.GameSessionIDFromToken(token)
+	if err != nil {
+		return models.GameSession{}, errGameSessionInvalid
+	}
+
+	var session models.GameSession
+	err = db.GameSessionColl.FindOne(ctx, bson.M{"_id": sessionID}).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return models.GameSession{}, errGameSessionInvalid
+		}
+		return models.GameSession{}, err
+	}
+
+	if !auth.VerifyGameSession(token, session) || session.UserID != userID || session.GameCode != gameType.GameCode {
+		return models.GameSession{}, errGameSessionInvalid
+	}
+	if session.UsedAt != nil {
+		return models.GameSession{}, errGameSessionUsed
+	}
+
+	now := time.Now()
+	if now.After(session.ExpiresAt) {
+		return models.GameSession{}, errGameSessionExpired
+	}
+	if now.Sub(session.IssuedAt) < time.Duration(gameType.MinDuration)*time.Second {
+		return models.GameSession{}, errGameSessionTooShort
+	}
+
+	// Mark the session as used, unless a concurrent request got there first
+	err = db.GameSessionColl.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": session.ID, "used_at": nil},
+		bson.M{"$set": bson.M{"used_at": now}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return models.GameSession{}, errGameSessionUsed
+		}
+		return models.GameSession{}, err
+	}
+
+	return session, nil
+}
+
+// releaseGameSession makes a claimed session usable again after the score it
+// was claimed for could not be saved. It uses its own context, since the
+// request's context may be what failed.
+func releaseGameSession(session models.GameSession) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := db.GameSessionColl.UpdateOne(
+		ctx,
+		bson.M{"_id": session.ID, "used_at": session.UsedAt, "score_id": nil},
+		bson.M{"$unset": bson.M{"used_at": ""}},
+	)
+	if err != nil {
+		log.Printf("Error releasing game session %s: %v", session.ID.Hex(), err)
+	}
+}
+
+// gameSessionErrorStatus maps a claimGameSession error to an HTTP status
+func gameSessionErrorStatus(err error) int {
+	switch err {
+	case errGameSessionRequired, errGameSessionInvalid, errGameSessionExpired, errGameSessionTooShort:
+		return http.StatusBadRequest
+	case errGameSessionUsed:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// newGameSeed returns a random non-negative seed for the client's game logic
+func newGameSeed() (int64, error) {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(buf[:]) >> 1), nil
+}
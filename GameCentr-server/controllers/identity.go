@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"net/http"
+	"netgames-go-server/auth"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// resolveActingUser returns the authenticated caller for a write request.
+// A body-supplied identity is optional, but when present it must match the
+// caller; otherwise the response is written and ok is false.
+func resolveActingUser(c *gin.Context, claimed primitive.ObjectID) (primitive.ObjectID, bool) {
+	userID, ok := auth.CurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Authentication required",
+		})
+		return primitive.NilObjectID, false
+	}
+
+	if !claimed.IsZero() && claimed != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"message": "Request identity does not match the authenticated user",
+		})
+		return primitive.NilObjectID, false
+	}
+
+	return userID, true
+}
+
+// resolveClaimedUser is resolveActingUser for bodies that carry the user ID
+// as a hex string
+func resolveClaimedUser(c *gin.Context, claimedHex string) (primitive.ObjectID, bool) {
+	claimed := primitive.NilObjectID
+	if claimedHex != "" {
+		var err error
+		claimed, err = primitive.ObjectIDFromHex(claimedHex)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Invalid user ID",
+			})
+			return primitive.NilObjectID, false
+		}
+	}
+
+	return resolveActingUser(c, claimed)
+}
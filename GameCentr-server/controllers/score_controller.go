@@ -2,9 +2,12 @@ package controllers
 
 import (
 	"context"
+	"log"
 	"net/http"
+	"netgames-go-server/auth"
 	"netgames-go-server/db"
 	"netgames-go-server/models"
+	"netgames-go-server/services"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -64,7 +67,7 @@ func GetAllScores(c *gin.Context) {
 		// Populate comments
 		var commentsWithDetails []models.CommentWithUserDetails
 		if len(score.Comments) > 0 {
-			commentsCursor, err := db.CommentColl.Find(ctx, bson.M{"_id": bson.M{"$in": score.Comments}})
+			commentsCursor, err := db.CommentColl.Find(ctx, visibleComments(score.Comments))
 			if err == nil {
 				var comments []models.Comment
 				if err := commentsCursor.All(ctx, &comments); err == nil {
@@ -79,6 +82,7 @@ func GetAllScores(c *gin.Context) {
 								Score:     comment.Score,
 								Author:    commentAuthor.ToResponse(),
 								Text:      comment.Text,
+								Edited:    len(comment.Edits) > 0,
 								CreatedAt: comment.CreatedAt,
 								UpdatedAt: comment.UpdatedAt,
 							})
@@ -147,7 +151,7 @@ func GetAllGameScores(c *gin.Context) {
 		// Populate comments
 		var commentsWithDetails []models.CommentWithUserDetails
 		if len(score.Comments) > 0 {
-			commentsCursor, err := db.CommentColl.Find(ctx, bson.M{"_id": bson.M{"$in": score.Comments}})
+			commentsCursor, err := db.CommentColl.Find(ctx, visibleComments(score.Comments))
 			if err == nil {
 				var comments []models.Comment
 				if err := commentsCursor.All(ctx, &comments); err == nil {
@@ -162,6 +166,7 @@ func GetAllGameScores(c *gin.Context) {
 								Score:     comment.Score,
 								Author:    commentAuthor.ToResponse(),
 								Text:      comment.Text,
+								Edited:    len(comment.Edits) > 0,
 								CreatedAt: comment.CreatedAt,
 								UpdatedAt: comment.UpdatedAt,
 							})
@@ -235,7 +240,7 @@ func GetScoreById(c *gin.Context) {
 	// Populate comments
 	var commentsWithDetails []models.CommentWithUserDetails
 	if len(score.Comments) > 0 {
-		commentsCursor, err := db.CommentColl.Find(ctx, bson.M{"_id": bson.M{"$in": score.Comments}})
+		commentsCursor, err := db.CommentColl.Find(ctx, visibleComments(score.Comments))
 		if err == nil {
 			defer commentsCursor.Close(ctx)
 
@@ -250,6 +255,7 @@ func GetScoreById(c *gin.Context) {
 							Score:     comment.Score,
 							Author:    commentAuthor.ToResponse(),
 							Text:      comment.Text,
+							Edited:    len(comment.Edits) > 0,
 							CreatedAt: comment.CreatedAt,
 							UpdatedAt: comment.UpdatedAt,
 						})
@@ -287,11 +293,12 @@ func PostScore(c *gin.Context) {
 	gameCode := c.Param("gameCode")
 
 	var scoreRequest struct {
-		Owner    primitive.ObjectID      `json:"owner" binding:"required"`
+		Owner    primitive.ObjectID      `json:"owner"`
 		Game     string                  `json:"game"`
-		Value    int                     `json:"value" binding:"required"`
+		Value    *int                    `json:"value" binding:"required"`
 		Text     string                  `json:"text"`
 		Metadata map[string]interface{}  `json:"metadata,omitempty"`
+		Session  string                  `json:"session"`
 	}
 
 	if err := c.ShouldBindJSON(&scoreRequest); err != nil {
@@ -302,9 +309,15 @@ func PostScore(c *gin.Context) {
 		return
 	}
 
+	// The score always belongs to the authenticated caller
+	ownerID, ok := resolveActingUser(c, scoreRequest.Owner)
+	if !ok {
+		return
+	}
+
 	// Check if user exists
 	var user models.User
-	err := db.UserColl.FindOne(ctx, bson.M{"_id": scoreRequest.Owner}).Decode(&user)
+	err := db.UserColl.FindOne(ctx, bson.M{"_id": ownerID}).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusBadRequest, gin.H{
@@ -331,6 +344,73 @@ func PostScore(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
 			"message": "Game code is required",
+			"errors": []models.FieldError{
+				{Field: "game", Code: "required", Message: "game code is required"},
+			},
+		})
+		return
+	}
+
+	// Get game type to check the score against its rules
+	var gameType models.GameType
+	err = db.GameTypeColl.FindOne(ctx, bson.M{"game_code": game}).Decode(&gameType)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Unknown game code",
+				"errors": []models.FieldError{
+					{Field: "game", Code: "unknown_game", Message: "no game with code " + game + " exists"},
+				},
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// Disabled games keep their history but accept no new scores
+	if gameType.Disabled {
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"message": "Game is disabled",
+			"errors": []models.FieldError{
+				{Field: "game", Code: "game_disabled", Message: gameType.Name + " no longer accepts scores"},
+			},
+		})
+		return
+	}
+
+	// Check the value against the game's scoring type and the metadata against its schema
+	fieldErrors := append(gameType.ValidateScore(*scoreRequest.Value), gameType.ValidateMetadata(scoreRequest.Metadata)...)
+	if len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Score is not valid for this game",
+			"errors":  fieldErrors,
+		})
+		return
+	}
+
+	// Tag the score with the season running for this game, if any
+	season, err := services.ActiveSeason(ctx, gameType.GameCode, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// The score must be backed by an unused game session
+	session, err := claimGameSession(ctx, scoreRequest.Session, ownerID, gameType)
+	if err != nil {
+		c.JSON(gameSessionErrorStatus(err), gin.H{
+			"success": false,
+			"message": err.Error(),
 		})
 		return
 	}
@@ -338,19 +418,25 @@ func PostScore(c *gin.Context) {
 	// Create score
 	now := time.Now()
 	score := models.Score{
-		Owner:     scoreRequest.Owner,
+		Owner:     ownerID,
 		Game:      game,
-		Value:     scoreRequest.Value,
+		Value:     *scoreRequest.Value,
 		Text:      scoreRequest.Text,
 		Metadata:  scoreRequest.Metadata,
+		Session:   session.ID,
 		Comments:  []primitive.ObjectID{},
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
+	if season != nil {
+		score.Season = season.ID
+	}
 
-	// Insert score into database
+	// Insert score into database, handing the session back if that fails so
+	// the play can be submitted again
 	result, err := db.ScoreColl.InsertOne(ctx, score)
 	if err != nil {
+		releaseGameSession(session)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"message": err.Error(),
@@ -361,28 +447,50 @@ func PostScore(c *gin.Context) {
 	// Get the inserted score with ID
 	score.ID = result.InsertedID.(primitive.ObjectID)
 
+	// Link the session to the score it was used for
+	_, err = db.GameSessionColl.UpdateOne(ctx, bson.M{"_id": session.ID}, bson.M{"$set": bson.M{"score_id": score.ID}})
+	if err != nil {
+		log.Printf("Error linking game session %s to score %s: %v", session.ID.Hex(), score.ID.Hex(), err)
+	}
+
+	// Update the game's materialized leaderboards and the player's rating with the new score
+	services.RefreshGameLeaderboardsAsync(gameType)
+	services.UpdateRatingAsync(gameType, score)
+
 	// Add score to user scores array
 	_, err = db.UserColl.UpdateOne(
 		ctx,
-		bson.M{"_id": scoreRequest.Owner},
+		bson.M{"_id": ownerID},
 		bson.M{"$push": bson.M{"scores": score.ID}, "$set": bson.M{"updatedAt": now}},
 	)
+	userUpdated := err == nil
+
+	// Unlock achievements using the run's metadata as its progress. The score
+	// is already saved, so a failure here is only logged.
+	achievements, err := services.EvaluateAchievements(ctx, ownerID, game, score.Metadata)
 	if err != nil {
+		log.Printf("Error evaluating achievements for score %s: %v", score.ID.Hex(), err)
+		achievements = []models.AchievementWithDetails{}
+	}
+
+	if !userUpdated {
 		// This shouldn't fail the request, but log it
 		// In production, you might want to handle this differently
 		// such as removing the score if we can't update the user
 		c.JSON(http.StatusOK, gin.H{
-			"success": true,
-			"message": "Successfully added score but failed to update user record",
-			"data":    score,
+			"success":      true,
+			"message":      "Successfully added score but failed to update user record",
+			"data":         score,
+			"achievements": achievements,
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Successfully added score",
-		"data":    score,
+		"success":      true,
+		"message":      "Successfully added score",
+		"data":         score,
+		"achievements": achievements,
 	})
 }
 
@@ -400,7 +508,7 @@ func AddCommentToScore(c *gin.Context) {
     }
 
     var commentRequest struct {
-        Author primitive.ObjectID `json:"author" binding:"required"`
+        Author primitive.ObjectID `json:"author"`
         Text   string             `json:"text" binding:"required"`
     }
 
@@ -412,9 +520,15 @@ func AddCommentToScore(c *gin.Context) {
         return
     }
 
+    // The comment always belongs to the authenticated caller
+    authorID, ok := resolveActingUser(c, commentRequest.Author)
+    if !ok {
+        return
+    }
+
     // Check if user exists
     var user models.User
-    err = db.UserColl.FindOne(ctx, bson.M{"_id": commentRequest.Author}).Decode(&user)
+    err = db.UserColl.FindOne(ctx, bson.M{"_id": authorID}).Decode(&user)
     if err != nil {
         c.JSON(http.StatusBadRequest, gin.H{
             "success": false,
@@ -438,7 +552,7 @@ func AddCommentToScore(c *gin.Context) {
     now := time.Now()
     comment := models.Comment{
         Score:     objectId,
-        Author:    commentRequest.Author,
+        Author:    authorID,
         Text:      commentRequest.Text,
         CreatedAt: now,
         UpdatedAt: now,
@@ -476,3 +590,225 @@ func AddCommentToScore(c *gin.Context) {
         "data":    comment,
     })
 }
+
+// EditComment replaces the text of a comment, keeping the previous text in its
+// edit history. Only the author can edit a comment.
+func EditComment(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var commentRequest struct {
+		Text string `json:"text" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&commentRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userID, ok := resolveActingUser(c, primitive.NilObjectID)
+	if !ok {
+		return
+	}
+
+	comment, ok := findScoreComment(ctx, c, false)
+	if !ok {
+		return
+	}
+	if comment.Author != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"message": "Only the author can edit a comment",
+		})
+		return
+	}
+
+	// Only apply the edit to the version that was read, so concurrent edits
+	// cannot drop a version from the history
+	now := time.Now()
+	err := db.CommentColl.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": comment.ID, "text": comment.Text, "deletedAt": bson.M{"$exists": false}},
+		bson.M{
+			"$set":  bson.M{"text": commentRequest.Text, "updatedAt": now},
+			"$push": bson.M{"edits": models.CommentEdit{Text: comment.Text, EditedAt: now}},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&comment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusConflict, gin.H{
+				"success": false,
+				"message": "Comment was changed or deleted in the meantime",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// The replaced versions are only shown to moderators
+	comment.Edits = nil
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Successfully edited comment",
+		"data":    comment,
+	})
+}
+
+// DeleteComment removes a comment from its score, leaving a tombstone without
+// its text. Authors can delete their own comments, moderators any comment.
+func DeleteComment(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userID, ok := resolveActingUser(c, primitive.NilObjectID)
+	if !ok {
+		return
+	}
+
+	comment, ok := findScoreComment(ctx, c, false)
+	if !ok {
+		return
+	}
+	if comment.Author != userID {
+		role, err := auth.LookupRole(ctx, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": err.Error(),
+			})
+			return
+		}
+		if !auth.HasPermission(role, auth.PermModerateComments) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"message": "Only the author or a moderator can delete a comment",
+			})
+			return
+		}
+	}
+
+	// Turn the comment into a tombstone, keeping its last text in the history
+	now := time.Now()
+	err := db.CommentColl.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": comment.ID, "deletedAt": bson.M{"$exists": false}},
+		bson.M{
+			"$set":  bson.M{"text": "", "deletedAt": now, "deletedBy": userID, "updatedAt": now},
+			"$push": bson.M{"edits": models.CommentEdit{Text: comment.Text, EditedAt: now}},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&comment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"message": "Comment not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// Listings skip tombstones too, so the comment stays hidden even if this fails
+	_, err = db.ScoreColl.UpdateOne(
+		ctx,
+		bson.M{"_id": comment.Score},
+		bson.M{"$pull": bson.M{"comments": comment.ID}, "$set": bson.M{"updatedAt": now}},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// The deleted text lives on in the history, which only moderators may see
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Successfully deleted comment",
+		"data": gin.H{
+			"_id":       comment.ID,
+			"deletedAt": comment.DeletedAt,
+			"deletedBy": comment.DeletedBy,
+		},
+	})
+}
+
+// GetCommentHistory returns a comment with every replaced version of its text,
+// including deleted comments (moderators only)
+func GetCommentHistory(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	comment, ok := findScoreComment(ctx, c, true)
+	if !ok {
+		return
+	}
+	if comment.Edits == nil {
+		comment.Edits = []models.CommentEdit{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Successfully retrieved comment history",
+		"data":    comment,
+	})
+}
+
+// findScoreComment loads the comment in the URL, checking that it belongs to
+// the score and game in the URL, and responds with an error if it cannot.
+// Deleted comments are only found with includeDeleted.
+func findScoreComment(ctx context.Context, c *gin.Context, includeDeleted bool) (models.Comment, bool) {
+	scoreID, err := primitive.ObjectIDFromHex(c.Param("scoreId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid score ID"})
+		return models.Comment{}, false
+	}
+	commentID, err := primitive.ObjectIDFromHex(c.Param("commentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid comment ID"})
+		return models.Comment{}, false
+	}
+
+	count, err := db.ScoreColl.CountDocuments(ctx, bson.M{"_id": scoreID, "game": c.Param("gameCode")})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": err.Error()})
+		return models.Comment{}, false
+	}
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Score not found for this game"})
+		return models.Comment{}, false
+	}
+
+	var comment models.Comment
+	filter := bson.M{"_id": commentID, "score": scoreID}
+	if !includeDeleted {
+		filter["deletedAt"] = bson.M{"$exists": false}
+	}
+	if err := db.CommentColl.FindOne(ctx, filter).Decode(&comment); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Comment not found"})
+			return models.Comment{}, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": err.Error()})
+		return models.Comment{}, false
+	}
+	return comment, true
+}
+
+// visibleComments filters the given comments down to those not deleted
+func visibleComments(commentIDs []primitive.ObjectID) bson.M {
+	return bson.M{"_id": bson.M{"$in": commentIDs}, "deletedAt": bson.M{"$exists": false}}
+}
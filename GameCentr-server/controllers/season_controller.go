@@ -0,0 +1,416 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"netgames-go-server/db"
+	"netgames-go-server/models"
+	"netgames-go-server/services"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// seasonRequest is the body accepted when creating or updating a season
+type seasonRequest struct {
+	Name        string    `json:"name" binding:"required"`
+	Description string    `json:"description"`
+	StartsAt    time.Time `json:"starts_at" binding:"required"`
+	EndsAt      time.Time `json:"ends_at" binding:"required"`
+	GameCodes   []string  `json:"game_codes"`
+}
+
+// GetSeasons retrieves all seasons, most recent first
+func GetSeasons(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := db.SeasonColl.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "starts_at", Value: -1}}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var seasons []models.Season
+	if err := cursor.All(ctx, &seasons); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	now := time.Now()
+	response := []gin.H{}
+	for _, season := range seasons {
+		response = append(response, seasonResponse(season, now))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Successfully retrieved seasons",
+		"data":    response,
+	})
+}
+
+// GetSeason retrieves a single season
+func GetSeason(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	season, ok := findSeason(ctx, c, c.Param("seasonId"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Successfully retrieved season",
+		"data":    seasonResponse(season, time.Now()),
+	})
+}
+
+// pastSeasonStartMessage explains why a season cannot start in the past: scores
+// are tagged with a season when they are posted, so scores already posted in
+// its window would be missing from its standings
+const pastSeasonStartMessage = "A season cannot start in the past, since scores posted before it was scheduled are not part of it"
+
+// CreateSeason defines a new season (admin only)
+func CreateSeason(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var request seasonRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	now := time.Now()
+	if request.StartsAt.Before(now) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": pastSeasonStartMessage,
+		})
+		return
+	}
+
+	season := models.Season{
+		Name:        request.Name,
+		Description: request.Description,
+		StartsAt:    request.StartsAt,
+		EndsAt:      request.EndsAt,
+		GameCodes:   request.GameCodes,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if !validateSeason(ctx, c, &season) {
+		return
+	}
+
+	result, err := db.SeasonColl.InsertOne(ctx, season)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	season.ID = result.InsertedID.(primitive.ObjectID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Successfully created season",
+		"data":    seasonResponse(season, now),
+	})
+}
+
+// UpdateSeason changes a season (admin only). Once a season has started its
+// start and games are fixed, since scores have already been tagged with it.
+func UpdateSeason(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	season, ok := findSeason(ctx, c, c.Param("seasonId"))
+	if !ok {
+		return
+	}
+
+	var request seasonRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	now := time.Now()
+	switch season.Status(now) {
+	case models.SeasonArchived:
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"message": "Archived seasons cannot be changed",
+		})
+		return
+	case models.SeasonActive, models.SeasonEnded:
+		if !request.StartsAt.Equal(season.StartsAt) || !sameGameCodes(request.GameCodes, season.GameCodes) {
+			c.JSON(http.StatusConflict, gin.H{
+				"success": false,
+				"message": "The start and games of a season cannot change after it started",
+			})
+			return
+		}
+		// Scores up to now were already tagged with the season, and its final
+		// standings are archived once it ends
+		if !request.EndsAt.Equal(season.EndsAt) && (!now.Before(season.EndsAt) || request.EndsAt.Before(now)) {
+			c.JSON(http.StatusConflict, gin.H{
+				"success": false,
+				"message": "The end of a season cannot change after it ended or move into the past",
+			})
+			return
+		}
+	case models.SeasonUpcoming:
+		if !request.StartsAt.Equal(season.StartsAt) && request.StartsAt.Before(now) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": pastSeasonStartMessage,
+			})
+			return
+		}
+	}
+
+	season.Name = request.Name
+	season.Description = request.Description
+	season.StartsAt = request.StartsAt
+	season.EndsAt = request.EndsAt
+	season.GameCodes = request.GameCodes
+	season.UpdatedAt = now
+	if !validateSeason(ctx, c, &season) {
+		return
+	}
+
+	_, err := db.SeasonColl.ReplaceOne(ctx, bson.M{"_id": season.ID}, season)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Successfully updated season",
+		"data":    seasonResponse(season, now),
+	})
+}
+
+// GetSeasonStandings retrieves the standings of a game in a season. Archived
+// seasons return the frozen final standings, other seasons the live ranking.
+func GetSeasonStandings(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	season, ok := findSeason(ctx, c, c.Param("seasonId"))
+	if !ok {
+		return
+	}
+
+	gameCode := c.Param("gameCode")
+	if !season.IncludesGame(gameCode) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"message": "Game is not part of this season",
+		})
+		return
+	}
+
+	var gameType models.GameType
+	err := db.GameTypeColl.FindOne(ctx, bson.M{"game_code": gameCode}).Decode(&gameType)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"message": "Game type not found",
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	if limit > services.SeasonStandingsSize {
+		limit = services.SeasonStandingsSize
+	}
+
+	var entries []models.LeaderboardEntry
+	var totalPlayers int64
+	final := season.ArchivedAt != nil
+	if final {
+		var standing models.SeasonStanding
+		err = db.SeasonStandingColl.FindOne(ctx, bson.M{"season_id": season.ID, "game_code": gameCode}).Decode(&standing)
+		if err != nil && err != mongo.ErrNoDocuments {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": err.Error(),
+			})
+			return
+		}
+		entries = standing.Entries
+		totalPlayers = standing.TotalPlayers
+		if len(entries) > limit {
+			entries = entries[:limit]
+		}
+	} else {
+		filter := services.ScoreFilter(gameCode, services.SeasonTimeFrame(season))
+		entries, err = services.TopEntries(ctx, filter, gameType, limit, true)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": err.Error(),
+			})
+			return
+		}
+		totalPlayers, err = services.CountPlayers(ctx, filter, gameType, bson.M{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": err.Error(),
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Successfully retrieved season standings",
+		"data": gin.H{
+			"season":       seasonResponse(season, time.Now()),
+			"gameCode":     gameCode,
+			"final":        final,
+			"totalPlayers": totalPlayers,
+			"entries":      leaderboardEntriesResponse(entries, gameType),
+		},
+	})
+}
+
+// findSeason loads a season by its hex ID, responding with an error if it cannot
+func findSeason(ctx context.Context, c *gin.Context, seasonId string) (models.Season, bool) {
+	objectId, err := primitive.ObjectIDFromHex(seasonId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid season ID",
+		})
+		return models.Season{}, false
+	}
+
+	var season models.Season
+	err = db.SeasonColl.FindOne(ctx, bson.M{"_id": objectId}).Decode(&season)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"message": "Season not found",
+			})
+			return models.Season{}, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return models.Season{}, false
+	}
+	return season, true
+}
+
+// validateSeason checks a season's dates, games and overlap with other
+// seasons, responding with an error if it is not valid
+func validateSeason(ctx context.Context, c *gin.Context, season *models.Season) bool {
+	if !season.EndsAt.After(season.StartsAt) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "A season must end after it starts",
+		})
+		return false
+	}
+
+	// Store no games as an empty list so it matches every game
+	if season.GameCodes == nil {
+		season.GameCodes = []string{}
+	}
+	if len(season.GameCodes) > 0 {
+		count, err := db.GameTypeColl.CountDocuments(ctx, bson.M{"game_code": bson.M{"$in": season.GameCodes}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": err.Error(),
+			})
+			return false
+		}
+		if int(count) != len(season.GameCodes) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Unknown or duplicate game code in game_codes",
+			})
+			return false
+		}
+	}
+
+	if err := services.CheckSeasonOverlap(ctx, *season); err != nil {
+		status := http.StatusInternalServerError
+		if err == services.ErrSeasonOverlap {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// sameGameCodes reports whether two game code lists contain the same games
+func sameGameCodes(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]bool, len(a))
+	for _, code := range a {
+		seen[code] = true
+	}
+	for _, code := range b {
+		if !seen[code] {
+			return false
+		}
+	}
+	return true
+}
+
+// seasonResponse adds the season's current status to its fields
+func seasonResponse(season models.Season, now time.Time) gin.H {
+	return gin.H{
+		"_id":         season.ID,
+		"name":        season.Name,
+		"description": season.Description,
+		"starts_at":   season.StartsAt,
+		"ends_at":     season.EndsAt,
+		"game_codes":  season.GameCodes,
+		"archived_at": season.ArchivedAt,
+		"status":      season.Status(now),
+	}
+}
@@ -0,0 +1,253 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"netgames-go-server/auth"
+	"netgames-go-server/db"
+	"netgames-go-server/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RefreshSession exchanges a refresh token for a new access and refresh token pair
+func RefreshSession(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var refreshData struct {
+		RefreshToken string `json:"refreshToken" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&refreshData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	session, refreshToken, err := auth.RotateSession(ctx, refreshData.RefreshToken)
+	if err != nil {
+		switch err {
+		case auth.ErrInvalidRefreshToken, auth.ErrSessionRevoked, auth.ErrRefreshTokenReused:
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"message": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+
+	// Load the user so the new access token reflects its current state
+	var user models.User
+	err = db.UserColl.FindOne(ctx, bson.M{"_id": session.UserID}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"message": "User not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	accessToken, expiresAt, err := auth.IssueAccessToken(user, session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Error issuing access token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":          true,
+		"message":          "Successfully refreshed session",
+		"data":             user.ToResponse(),
+		"accessToken":      accessToken,
+		"tokenType":        "Bearer",
+		"expiresAt":        expiresAt,
+		"refreshToken":     refreshToken,
+		"refreshExpiresAt": session.ExpiresAt,
+	})
+}
+
+// GetSessions lists the active sessions of the authenticated user
+func GetSessions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userID, ok := resolveActingUser(c, primitive.NilObjectID)
+	if !ok {
+		return
+	}
+	currentSessionID, _ := auth.CurrentSessionID(c)
+
+	// Set options to sort by last use in descending order (-1)
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "last_used_at", Value: -1}})
+
+	cursor, err := db.SessionColl.Find(ctx, bson.M{
+		"user_id":    userID,
+		"revoked_at": nil,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}, findOptions)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var sessions []models.Session
+	if err := cursor.All(ctx, &sessions); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	sessionList := []gin.H{}
+	for _, session := range sessions {
+		sessionList = append(sessionList, gin.H{
+			"id":         session.ID,
+			"userAgent":  session.UserAgent,
+			"ipAddress":  session.IPAddress,
+			"createdAt":  session.CreatedAt,
+			"lastUsedAt": session.LastUsedAt,
+			"expiresAt":  session.ExpiresAt,
+			"current":    session.ID == currentSessionID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Successfully retrieved sessions",
+		"data":    sessionList,
+	})
+}
+
+// RevokeSession revokes one of the authenticated user's sessions
+func RevokeSession(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userID, ok := resolveActingUser(c, primitive.NilObjectID)
+	if !ok {
+		return
+	}
+
+	sessionID, err := primitive.ObjectIDFromHex(c.Param("sessionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid session ID",
+		})
+		return
+	}
+
+	// Users may only revoke their own sessions
+	count, err := db.SessionColl.CountDocuments(ctx, bson.M{"_id": sessionID, "user_id": userID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"message": "Session not found",
+		})
+		return
+	}
+
+	if err := auth.RevokeSession(ctx, sessionID, "revoked by user"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Successfully revoked session",
+	})
+}
+
+// Logout revokes the session the caller's access token belongs to
+func Logout(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sessionID, ok := auth.CurrentSessionID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Authentication required",
+		})
+		return
+	}
+
+	if err := auth.RevokeSession(ctx, sessionID, "logout"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Successfully logged out",
+	})
+}
+
+// LogoutAll revokes every session of the authenticated user
+func LogoutAll(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userID, ok := resolveActingUser(c, primitive.NilObjectID)
+	if !ok {
+		return
+	}
+
+	revoked, err := auth.RevokeAllSessions(ctx, userID, "logout everywhere")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Successfully logged out of all sessions",
+		"data": gin.H{
+			"revokedSessions": revoked,
+		},
+	})
+}
@@ -3,8 +3,10 @@ package controllers
 import (
 	"context"
 	"net/http"
+	"netgames-go-server/auth"
 	"netgames-go-server/db"
 	"netgames-go-server/models"
+	"netgames-go-server/services"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -45,6 +47,9 @@ func AddUser(c *gin.Context) {
 	user.UpdatedAt = now
 	user.Scores = []primitive.ObjectID{}
 
+	// New accounts always start as players
+	user.Role = models.RolePlayer
+
 	// Hash the password
 	if err := user.HashPassword(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -119,10 +124,34 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	// Start a new session and issue its tokens
+	session, refreshToken, err := auth.CreateSession(ctx, user.ID, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Error creating session",
+		})
+		return
+	}
+
+	accessToken, expiresAt, err := auth.IssueAccessToken(user, session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Error issuing access token",
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Found user",
-		"data":    user.ToResponse(),
+		"success":          true,
+		"message":          "Found user",
+		"data":             user.ToResponse(),
+		"accessToken":      accessToken,
+		"tokenType":        "Bearer",
+		"expiresAt":        expiresAt,
+		"refreshToken":     refreshToken,
+		"refreshExpiresAt": session.ExpiresAt,
 	})
 }
 
@@ -212,6 +241,131 @@ func GetUserById(c *gin.Context) {
 	})
 }
 
+// GetUserAdminView retrieves a user together with their achievement award and
+// revocation history (admin only)
+func GetUserAdminView(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userId := c.Param("userId")
+	objectId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid user ID",
+		})
+		return
+	}
+
+	var user models.User
+	err = db.UserColl.FindOne(ctx, bson.M{"_id": objectId}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"message": "User not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	history, err := services.GetAchievementAudit(ctx, objectId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Successfully retrieved the user",
+		"data": gin.H{
+			"user":               user.ToResponse(),
+			"achievementHistory": history,
+		},
+	})
+}
+
+// SetUserRole changes the role of a user
+func SetUserRole(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userId := c.Param("userId")
+	objectId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid user ID",
+		})
+		return
+	}
+
+	var roleData struct {
+		Role string `json:"role" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&roleData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if !models.IsValidRole(roleData.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid role",
+		})
+		return
+	}
+
+	// Prevent admins from locking themselves out
+	if callerID, ok := auth.CurrentUserID(c); ok && callerID == objectId && roleData.Role != models.RoleAdmin {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Admins cannot remove their own admin role",
+		})
+		return
+	}
+
+	var user models.User
+	err = db.UserColl.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": objectId},
+		bson.M{"$set": bson.M{"role": roleData.Role, "updatedAt": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"message": "User not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Successfully updated user role",
+		"data":    user.ToResponse(),
+	})
+}
+
 // GetUserScores retrieves all scores associated with a user
 func GetUserScores(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -289,7 +443,7 @@ func GetUserScores(c *gin.Context) {
 		// Populate comments
 		var commentsWithDetails []models.CommentWithUserDetails
 		if len(score.Comments) > 0 {
-			commentsCursor, err := db.CommentColl.Find(ctx, bson.M{"_id": bson.M{"$in": score.Comments}})
+			commentsCursor, err := db.CommentColl.Find(ctx, visibleComments(score.Comments))
 			if err == nil {
 				defer commentsCursor.Close(ctx)
 
@@ -304,6 +458,7 @@ func GetUserScores(c *gin.Context) {
 								Score:     comment.Score,
 								Author:    commentAuthor.ToResponse(),
 								Text:      comment.Text,
+								Edited:    len(comment.Edits) > 0,
 								CreatedAt: comment.CreatedAt,
 								UpdatedAt: comment.UpdatedAt,
 							})
@@ -0,0 +1,95 @@
+package db
+
+import "netgames-go-server/models"
+
+// achievementCriteria holds the unlock rule of each seeded achievement, keyed
+// by "<game_code>/<code>". Rules compare the per-run facts reported with a game
+// event; counters computed by the server live under "stats" and the server
+// time under "server".
+var achievementCriteria = map[string]*models.Criteria{
+	// Color Guess
+	"colorguess/sharp_eyes":     all(eq("correctAnswers", 20), eq("mistakes", 0)),
+	"colorguess/quick_colors":   lt("timeSpent", 30),
+	"colorguess/color_novice":   eq("completed", true),
+	"colorguess/rainbow_master": gte("stats.playCount", 50),
+
+	// Guess
+	"guess/lucky_guess":   all(eq("guessCount", 1), eq("correct", true)),
+	"guess/close_call":    lte("distance", 2),
+	"guess/persistence":   gte("guessCount", 10),
+	"guess/binary_search": all(lte("guessCount", 7), eq("correct", true)),
+
+	// Hangman
+	"hangman/word_wizard":    all(eq("incorrectGuesses", 0), eq("solved", true)),
+	"hangman/last_chance":    all(eq("remainingGuesses", 1), eq("solved", true)),
+	"hangman/vowel_master":   eq("allVowelsGuessed", true),
+	"hangman/hangman_savior": gte("stats.winCount", 10),
+
+	// Memory Match
+	"memorymatch/photographic_memory": all(eq("incorrectMatches", 0), eq("completed", true)),
+	"memorymatch/speed_matcher":       all(lt("timeSpent", 30), eq("completed", true)),
+	"memorymatch/memory_novice":       eq("completed", true),
+	"memorymatch/memory_streak":       gte("streak", 5),
+
+	// Pattern Repeater
+	"patternrepeater/pattern_master": gte("level", 20),
+	"patternrepeater/rhythm_king":    eq("perfectTiming", true),
+	"patternrepeater/pattern_novice": gte("level", 5),
+	"patternrepeater/pattern_addict": gte("stats.playCount", 20),
+
+	// Pong
+	"pong/pong_champion": gte("score", 50),
+	"pong/pong_rally":    gte("rallyLength", 20),
+	"pong/pong_novice":   gte("score", 1),
+	"pong/pong_comeback": all(eq("comeback", true), gte("deficitOvercome", 5)),
+
+	// Quick Math
+	"quickmath/math_genius":      gte("streak", 15),
+	"quickmath/speed_calculator": all(gte("questionsAnswered", 10), lt("timeSpent", 20)),
+	"quickmath/math_novice":      eq("completed", true),
+	"quickmath/math_addict":      gte("stats.playCount", 30),
+
+	// Simon Says
+	"simonsays/simon_master":     gte("level", 15),
+	"simonsays/simon_streak":     gte("streak", 5),
+	"simonsays/simon_novice":     gte("level", 5),
+	"simonsays/simon_says_dance": eq("perfectTiming", true),
+
+	// Typing
+	"typing/typing_master":   gte("wpm", 80),
+	"typing/perfect_typist":  all(eq("errors", 0), eq("completed", true)),
+	"typing/typing_novice":   eq("completed", true),
+	"typing/typing_marathon": gte("stats.totalWords", 1000),
+
+	// Whack-a-Mole
+	"whackamole/mole_hunter":        gte("stats.totalMolesWhacked", 50),
+	"whackamole/mole_hunter_silver": gte("stats.totalMolesWhacked", 250),
+	"whackamole/mole_hunter_gold":   gte("stats.totalMolesWhacked", 1000),
+	"whackamole/quick_reflexes":     gte("streak", 10),
+	"whackamole/mole_novice":        gte("molesWhacked", 1),
+	"whackamole/mole_frenzy":        eq("frenzy", true),
+
+	// Easter eggs
+	"all/night_owl":          lt("server.hour", 4),
+	"all/game_hopper":        gte("stats.gamesPlayedToday", 10),
+	"all/achievement_hunter": gte("stats.achievementCount", 20),
+}
+
+// all matches when every rule matches
+func all(rules ...*models.Criteria) *models.Criteria {
+	criteria := &models.Criteria{}
+	for _, rule := range rules {
+		criteria.All = append(criteria.All, *rule)
+	}
+	return criteria
+}
+
+// compare builds a rule comparing a fact with a value
+func compare(field, op string, value interface{}) *models.Criteria {
+	return &models.Criteria{Field: field, Op: op, Value: value}
+}
+
+func eq(field string, value interface{}) *models.Criteria { return compare(field, models.OpEq, value) }
+func lt(field string, value float64) *models.Criteria     { return compare(field, models.OpLt, value) }
+func lte(field string, value float64) *models.Criteria    { return compare(field, models.OpLte, value) }
+func gte(field string, value float64) *models.Criteria    { return compare(field, models.OpGte, value) }
@@ -11,14 +11,26 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
+// DatabaseName is the MongoDB database used by the server
+const DatabaseName = "netgames"
+
 var (
-	Client              *mongo.Client
-	UserColl            *mongo.Collection
-	ScoreColl           *mongo.Collection
-	CommentColl         *mongo.Collection
-	GameTypeColl        *mongo.Collection
-	AchievementColl     *mongo.Collection
-	UserAchievementColl *mongo.Collection
+	Client                  *mongo.Client
+	UserColl                *mongo.Collection
+	ScoreColl               *mongo.Collection
+	CommentColl             *mongo.Collection
+	GameTypeColl            *mongo.Collection
+	AchievementColl         *mongo.Collection
+	UserAchievementColl     *mongo.Collection
+	SessionColl             *mongo.Collection
+	GameSessionColl         *mongo.Collection
+	LeaderboardColl         *mongo.Collection
+	SeasonColl              *mongo.Collection
+	SeasonStandingColl      *mongo.Collection
+	RatingColl              *mongo.Collection
+	AchievementProgressColl *mongo.Collection
+	AchievementAuditColl    *mongo.Collection
+	RevokedAchievementColl  *mongo.Collection
 )
 
 // ConnectDB establishes connection to MongoDB and sets up collections
@@ -50,13 +62,22 @@ func ConnectDB() {
 	Client = client
 
 	// Initialize collections
-	dbName := "netgames" // using fixed name, could be from env vars
+	dbName := DatabaseName // using fixed name, could be from env vars
 	UserColl = Client.Database(dbName).Collection("users")
 	ScoreColl = Client.Database(dbName).Collection("scores")
 	CommentColl = Client.Database(dbName).Collection("comments")
 	GameTypeColl = Client.Database(dbName).Collection("game_types")
 	AchievementColl = Client.Database(dbName).Collection("achievements")
 	UserAchievementColl = Client.Database(dbName).Collection("user_achievements")
+	AchievementProgressColl = Client.Database(dbName).Collection("achievement_progress")
+	AchievementAuditColl = Client.Database(dbName).Collection("achievement_audit")
+	RevokedAchievementColl = Client.Database(dbName).Collection("revoked_achievements")
+	SessionColl = Client.Database(dbName).Collection("sessions")
+	GameSessionColl = Client.Database(dbName).Collection("game_sessions")
+	LeaderboardColl = Client.Database(dbName).Collection("leaderboards")
+	SeasonColl = Client.Database(dbName).Collection("seasons")
+	SeasonStandingColl = Client.Database(dbName).Collection("season_standings")
+	RatingColl = Client.Database(dbName).Collection("ratings")
 
 	log.Println("Connected to MongoDB")
 	
@@ -68,6 +89,10 @@ func ConnectDB() {
 	if err := InitGameScoreIndexes(client, dbName); err != nil {
 		log.Printf("Warning: Failed to create game score indexes: %v", err)
 	}
+
+	if err := InitGameSessionIndexes(client, dbName); err != nil {
+		log.Printf("Warning: Failed to create game session indexes: %v", err)
+	}
 	
 	// Initialize achievements and create indexes
 	if err := InitAchievements(client, dbName); err != nil {
@@ -77,6 +102,39 @@ func ConnectDB() {
 	if err := InitAchievementIndexes(client, dbName); err != nil {
 		log.Printf("Warning: Failed to create achievement indexes: %v", err)
 	}
+
+	if err := InitAchievementProgressIndexes(client, dbName); err != nil {
+		log.Printf("Warning: Failed to create achievement progress indexes: %v", err)
+	}
+
+	if err := InitAchievementAuditIndexes(client, dbName); err != nil {
+		log.Printf("Warning: Failed to create achievement audit indexes: %v", err)
+	}
+
+	if err := InitRevokedAchievementIndexes(client, dbName); err != nil {
+		log.Printf("Warning: Failed to create revoked achievement indexes: %v", err)
+	}
+
+	if err := InitSessionIndexes(client, dbName); err != nil {
+		log.Printf("Warning: Failed to create session indexes: %v", err)
+	}
+
+	if err := InitLeaderboardIndexes(client, dbName); err != nil {
+		log.Printf("Warning: Failed to create leaderboard indexes: %v", err)
+	}
+
+	if err := InitSeasonIndexes(client, dbName); err != nil {
+		log.Printf("Warning: Failed to create season indexes: %v", err)
+	}
+
+	if err := InitRatingIndexes(client, dbName); err != nil {
+		log.Printf("Warning: Failed to create rating indexes: %v", err)
+	}
+
+	// Bootstrap the first admin if one is configured
+	if err := InitAdmin(client, dbName); err != nil {
+		log.Printf("Warning: Failed to initialize admin user: %v", err)
+	}
 }
 
 // DisconnectDB closes the MongoDB connection
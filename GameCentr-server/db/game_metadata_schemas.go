@@ -0,0 +1,117 @@
+package db
+
+import "netgames-go-server/models"
+
+// gameMetadataSchemas describes the metadata each game sends with its scores.
+// Extra fields are allowed so older clients keep working, but declared fields
+// must have the right type and range.
+var gameMetadataSchemas = map[string]*models.MetadataSchema{
+	"colorguess": metadataSchema(map[string]models.MetadataProperty{
+		"correctAnswers": integerProperty("Number of colors named correctly", 0, floatPtr(20)),
+		"mistakes":       integerProperty("Number of wrong answers", 0, nil),
+		"timeSpent":      numberProperty("Time spent in seconds", 0),
+		"completed":      booleanProperty("Whether the game was finished"),
+	}),
+	"guess": metadataSchema(map[string]models.MetadataProperty{
+		"target":     integerProperty("The number that had to be guessed", 1, floatPtr(100)),
+		"attempts":   integerProperty("Attempts used", 0, nil),
+		"guessCount": integerProperty("Number of guesses made", 0, nil),
+		"correct":    booleanProperty("Whether the number was guessed"),
+		"distance":   integerProperty("Distance of the last guess from the target", 0, nil),
+		"range":      objectProperty("The range the number was picked from"),
+	}),
+	"hangman": metadataSchema(map[string]models.MetadataProperty{
+		"incorrectGuesses": integerProperty("Number of wrong letters guessed", 0, nil),
+		"remainingGuesses": integerProperty("Wrong guesses left when the game ended", 0, nil),
+		"solved":           booleanProperty("Whether the word was solved"),
+		"allVowelsGuessed": booleanProperty("Whether every vowel in the word was guessed"),
+	}),
+	"memorymatch": metadataSchema(map[string]models.MetadataProperty{
+		"moves":            integerProperty("Number of moves made", 0, nil),
+		"incorrectMatches": integerProperty("Number of pairs flipped that did not match", 0, nil),
+		"timeSpent":        numberProperty("Time spent in seconds", 0),
+		"streak":           integerProperty("Longest run of matches without a mistake", 0, nil),
+		"completed":        booleanProperty("Whether every pair was matched"),
+	}),
+	"patternrepeater": metadataSchema(map[string]models.MetadataProperty{
+		"level":         integerProperty("Highest level reached", 0, nil),
+		"perfectTiming": booleanProperty("Whether a pattern was repeated with perfect timing"),
+	}),
+	"pong": metadataSchema(map[string]models.MetadataProperty{
+		"score":           integerProperty("Points scored", 0, nil),
+		"rallyLength":     integerProperty("Longest rally in hits", 0, nil),
+		"comeback":        booleanProperty("Whether the game was won from behind"),
+		"deficitOvercome": integerProperty("Largest deficit that was overcome", 0, nil),
+	}),
+	"quickmath": metadataSchema(map[string]models.MetadataProperty{
+		"streak":            integerProperty("Longest run of correct answers", 0, nil),
+		"questionsAnswered": integerProperty("Number of questions answered", 0, nil),
+		"timeSpent":         numberProperty("Time spent in seconds", 0),
+		"completed":         booleanProperty("Whether the game was finished"),
+	}),
+	"simonsays": metadataSchema(map[string]models.MetadataProperty{
+		"level":         integerProperty("Highest level reached", 0, nil),
+		"streak":        integerProperty("Games completed in a row", 0, nil),
+		"perfectTiming": booleanProperty("Whether a level was completed with perfect timing"),
+	}),
+	"typing": metadataSchema(map[string]models.MetadataProperty{
+		"wpm":        numberProperty("Typing speed in words per minute", 0),
+		"errors":     integerProperty("Number of typing errors", 0, nil),
+		"wordsTyped": integerProperty("Number of words typed", 0, nil),
+		"completed":  booleanProperty("Whether the game was finished"),
+	}),
+	"whackamole": metadataSchema(map[string]models.MetadataProperty{
+		"molesWhacked": integerProperty("Number of moles whacked", 0, nil),
+		"streak":       integerProperty("Longest run of hits without a miss", 0, nil),
+		"frenzy":       booleanProperty("Whether 5 moles were whacked within 3 seconds"),
+	}),
+}
+
+// metadataSchema builds an object schema that allows undeclared fields
+func metadataSchema(properties map[string]models.MetadataProperty) *models.MetadataSchema {
+	return &models.MetadataSchema{
+		Type:                 models.SchemaObject,
+		Properties:           properties,
+		AdditionalProperties: true,
+	}
+}
+
+// integerProperty describes a whole number field with an optional upper bound
+func integerProperty(description string, minimum float64, maximum *float64) models.MetadataProperty {
+	return models.MetadataProperty{
+		Type:        models.SchemaInteger,
+		Description: description,
+		Minimum:     floatPtr(minimum),
+		Maximum:     maximum,
+	}
+}
+
+// numberProperty describes a non-integer numeric field
+func numberProperty(description string, minimum float64) models.MetadataProperty {
+	return models.MetadataProperty{
+		Type:        models.SchemaNumber,
+		Description: description,
+		Minimum:     floatPtr(minimum),
+	}
+}
+
+// booleanProperty describes a true/false field
+func booleanProperty(description string) models.MetadataProperty {
+	return models.MetadataProperty{
+		Type:        models.SchemaBoolean,
+		Description: description,
+	}
+}
+
+// objectProperty describes a nested object field
+func objectProperty(description string) models.MetadataProperty {
+	return models.MetadataProperty{
+		Type:        models.SchemaObject,
+		Description: description,
+	}
+}
+
+// floatPtr returns a pointer to the given float64 value
+func floatPtr(f float64) *float64 {
+	return &f
+}
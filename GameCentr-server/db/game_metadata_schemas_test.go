@@ -0,0 +1,38 @@
+package db
+
+import (
+	"encoding/json"
+	"testing"
+
+	"netgames-go-server/models"
+)
+
+// TestClientMetadataMatchesSchemas checks the metadata the game clients post,
+// decoded as PostScore decodes it, against the seeded schemas
+func TestClientMetadataMatchesSchemas(t *testing.T) {
+	tests := []struct {
+		game      string
+		body      string
+		wantValid bool
+	}{
+		{"typing", `{"wordsTyped": 42}`, true},
+		{"typing", `{"wordsTyped": -1}`, false},
+		{"typing", `{"wordsTyped": 4.5}`, false},
+		{"whackamole", `{"molesWhacked": 17}`, true},
+		{"whackamole", `{"molesWhacked": "17"}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.game+" "+tt.body, func(t *testing.T) {
+			var metadata map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.body), &metadata); err != nil {
+				t.Fatal(err)
+			}
+			gameType := models.GameType{GameCode: tt.game, MetadataSchema: gameMetadataSchemas[tt.game]}
+			errs := gameType.ValidateMetadata(metadata)
+			if valid := len(errs) == 0; valid != tt.wantValid {
+				t.Errorf("ValidateMetadata(%s) = %v, want valid %v", tt.body, errs, tt.wantValid)
+			}
+		})
+	}
+}
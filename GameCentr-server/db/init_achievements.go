@@ -11,6 +11,10 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// achievementSeedVersion is stored on seeded achievements. Bump it when the
+// seeds gain fields that achievements seeded earlier must be given once.
+const achievementSeedVersion = 1
+
 // InitAchievements initializes the achievements collection with predefined achievements
 func InitAchievements(client *mongo.Client, dbName string) error {
 	collection := client.Database(dbName).Collection("achievements")
@@ -18,7 +22,7 @@ func InitAchievements(client *mongo.Client, dbName string) error {
 	// Create indexes
 	indexModels := []mongo.IndexModel{
 		{
-			Keys: bson.D{{Key: "game_code", Value: 1}, {Key: "code", Value: 1}},
+			Keys:    bson.D{{Key: "game_code", Value: 1}, {Key: "code", Value: 1}},
 			Options: options.Index().SetUnique(true),
 		},
 	}
@@ -217,15 +221,16 @@ func InitAchievements(client *mongo.Client, dbName string) error {
 
 		// Pattern Repeater Achievements
 		{
-			GameCode:    "patternrepeater",
-			Code:        "pattern_master",
-			Title:       "Pattern Master",
-			Description: "Reach level 20 in Pattern Repeater",
-			Icon:        "🧩",
-			Difficulty:  "hard",
-			IsHidden:    false,
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
+			GameCode:      "patternrepeater",
+			Code:          "pattern_master",
+			Title:         "Pattern Master",
+			Description:   "Reach level 20 in Pattern Repeater",
+			Icon:          "🧩",
+			Difficulty:    "hard",
+			IsHidden:      false,
+			Prerequisites: []string{"pattern_novice"},
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
 		},
 		{
 			GameCode:    "patternrepeater",
@@ -355,15 +360,16 @@ func InitAchievements(client *mongo.Client, dbName string) error {
 
 		// Simon Says Achievements
 		{
-			GameCode:    "simonsays",
-			Code:        "simon_master",
-			Title:       "Simon Master",
-			Description: "Reach level 15 in Simon Says",
-			Icon:        "🎯",
-			Difficulty:  "hard",
-			IsHidden:    false,
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
+			GameCode:      "simonsays",
+			Code:          "simon_master",
+			Title:         "Simon Master",
+			Description:   "Reach level 15 in Simon Says",
+			Icon:          "🎯",
+			Difficulty:    "hard",
+			IsHidden:      false,
+			Prerequisites: []string{"simon_novice"},
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
 		},
 		{
 			GameCode:    "simonsays",
@@ -450,10 +456,38 @@ func InitAchievements(client *mongo.Client, dbName string) error {
 			GameCode:    "whackamole",
 			Code:        "mole_hunter",
 			Title:       "Mole Hunter",
-			Description: "Whack 50 moles in a single game",
+			Description: "Whack 50 moles",
+			Icon:        "🔨",
+			Difficulty:  "easy",
+			IsHidden:    false,
+			TierGroup:   "mole_hunter",
+			Tier:        models.TierBronze,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		},
+		{
+			GameCode:    "whackamole",
+			Code:        "mole_hunter_silver",
+			Title:       "Mole Hunter",
+			Description: "Whack 250 moles",
+			Icon:        "🔨",
+			Difficulty:  "medium",
+			IsHidden:    false,
+			TierGroup:   "mole_hunter",
+			Tier:        models.TierSilver,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		},
+		{
+			GameCode:    "whackamole",
+			Code:        "mole_hunter_gold",
+			Title:       "Mole Hunter",
+			Description: "Whack 1000 moles",
 			Icon:        "🔨",
 			Difficulty:  "hard",
 			IsHidden:    false,
+			TierGroup:   "mole_hunter",
+			Tier:        models.TierGold,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		},
@@ -527,23 +561,98 @@ func InitAchievements(client *mongo.Client, dbName string) error {
 		},
 	}
 
-	// Insert achievements using upsert to avoid duplicates
-	for _, achievement := range achievements {
+	// Insert missing achievements only, so that changes made through the admin
+	// API survive a restart
+	for i, achievement := range achievements {
+		achievement.Criteria = achievementCriteria[achievement.GameCode+"/"+achievement.Code]
+		achievement.SortOrder = i + 1
+		achievement.SeedVersion = achievementSeedVersion
+
 		filter := bson.M{"game_code": achievement.GameCode, "code": achievement.Code}
-		update := bson.M{"$set": achievement}
+		update := bson.M{"$setOnInsert": achievement}
 		opts := options.Update().SetUpsert(true)
-		
+
 		_, err := collection.UpdateOne(context.Background(), filter, update, opts)
 		if err != nil {
 			log.Printf("Error upserting achievement %s: %v", achievement.Code, err)
 			return err
 		}
+
+		if err := backfillAchievement(collection, achievement); err != nil {
+			log.Printf("Error backfilling achievement %s: %v", achievement.Code, err)
+			return err
+		}
 	}
 
 	log.Println("Achievements initialized successfully")
 	return nil
 }
 
+// fieldBackfill sets a document's fields when the given field is missing
+type fieldBackfill struct {
+	field string
+	set   bson.M
+}
+
+// backfillAchievement gives an achievement seeded by an older version the
+// fields added since. It runs once per achievement, so fields an admin clears
+// later are not set again.
+func backfillAchievement(collection *mongo.Collection, achievement models.Achievement) error {
+	key := bson.M{"game_code": achievement.GameCode, "code": achievement.Code}
+	backfills := []fieldBackfill{
+		{"sort_order", bson.M{"sort_order": achievement.SortOrder}},
+	}
+	if achievement.Criteria != nil {
+		backfills = append(backfills, fieldBackfill{"criteria", bson.M{"criteria": achievement.Criteria}})
+	}
+	if len(achievement.Prerequisites) > 0 {
+		backfills = append(backfills, fieldBackfill{"prerequisites", bson.M{"prerequisites": achievement.Prerequisites}})
+	}
+	if achievement.TierGroup != "" {
+		// Seeds that became tiers also change what they ask for, so their
+		// criteria and wording are replaced together with the tier
+		backfills = append(backfills, fieldBackfill{"tier_group", bson.M{
+			"tier_group":  achievement.TierGroup,
+			"tier":        achievement.Tier,
+			"title":       achievement.Title,
+			"description": achievement.Description,
+			"difficulty":  achievement.Difficulty,
+			"criteria":    achievement.Criteria,
+		}})
+	}
+
+	return applySeedBackfills(collection, key, achievementSeedVersion, backfills)
+}
+
+// applySeedBackfills runs the backfills against the document matching key
+// unless it is already at the seed version, then marks it with that version
+func applySeedBackfills(collection *mongo.Collection, key bson.M, version int, backfills []fieldBackfill) error {
+	outdated := bson.M{"seed_version": bson.M{"$not": bson.M{"$gte": version}}}
+	for k, v := range key {
+		outdated[k] = v
+	}
+	if err := applyBackfills(collection, outdated, backfills); err != nil {
+		return err
+	}
+	_, err := collection.UpdateOne(context.Background(), outdated, bson.M{"$set": bson.M{"seed_version": version}})
+	return err
+}
+
+// applyBackfills runs each backfill against the document matching key
+func applyBackfills(collection *mongo.Collection, key bson.M, backfills []fieldBackfill) error {
+	for _, backfill := range backfills {
+		filter := bson.M{backfill.field: bson.M{"$exists": false}}
+		for k, v := range key {
+			filter[k] = v
+		}
+		_, err := collection.UpdateOne(context.Background(), filter, bson.M{"$set": backfill.set})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // InitAchievementIndexes creates indexes for the user_achievements collection
 func InitAchievementIndexes(client *mongo.Client, dbName string) error {
 	collection := client.Database(dbName).Collection("user_achievements")
@@ -577,3 +686,80 @@ func InitAchievementIndexes(client *mongo.Client, dbName string) error {
 	log.Println("User achievement indexes created successfully")
 	return nil
 }
+
+// InitAchievementProgressIndexes creates indexes for the achievement_progress collection
+func InitAchievementProgressIndexes(client *mongo.Client, dbName string) error {
+	collection := client.Database(dbName).Collection("achievement_progress")
+
+	// Create indexes
+	indexes := []mongo.IndexModel{
+		{
+			// One progress record per user and achievement
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "achievement_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(context.Background(), indexes)
+	if err != nil {
+		log.Printf("Error creating indexes on achievement_progress: %v", err)
+		return err
+	}
+
+	log.Println("Achievement progress indexes created successfully")
+	return nil
+}
+
+// InitAchievementAuditIndexes creates indexes for the achievement_audit collection
+func InitAchievementAuditIndexes(client *mongo.Client, dbName string) error {
+	collection := client.Database(dbName).Collection("achievement_audit")
+
+	// Create indexes
+	indexes := []mongo.IndexModel{
+		{
+			// A user's history, newest first
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(context.Background(), indexes)
+	if err != nil {
+		log.Printf("Error creating indexes on achievement_audit: %v", err)
+		return err
+	}
+
+	log.Println("Achievement audit indexes created successfully")
+	return nil
+}
+
+// InitRevokedAchievementIndexes creates indexes for the revoked_achievements collection
+func InitRevokedAchievementIndexes(client *mongo.Client, dbName string) error {
+	collection := client.Database(dbName).Collection("revoked_achievements")
+
+	// Create indexes
+	indexes := []mongo.IndexModel{
+		{
+			// One revocation marker per user and achievement
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "achievement_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(context.Background(), indexes)
+	if err != nil {
+		log.Printf("Error creating indexes on revoked_achievements: %v", err)
+		return err
+	}
+
+	log.Println("Revoked achievement indexes created successfully")
+	return nil
+}
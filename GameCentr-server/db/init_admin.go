@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"netgames-go-server/models"
+)
+
+// InitAdmin makes sure the user named by ADMIN_USERNAME is an admin.
+// The user is created with ADMIN_PASSWORD if it does not exist yet.
+func InitAdmin(client *mongo.Client, dbName string) error {
+	username := os.Getenv("ADMIN_USERNAME")
+	if username == "" {
+		return nil
+	}
+
+	return EnsureAdmin(client, dbName, username, os.Getenv("ADMIN_PASSWORD"))
+}
+
+// EnsureAdmin promotes an existing user to admin, or creates a new admin user
+// when no user with that username exists and a password is given
+func EnsureAdmin(client *mongo.Client, dbName, username, password string) error {
+	if username == "" {
+		return errors.New("admin username is required")
+	}
+
+	collection := client.Database(dbName).Collection("users")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	result, err := collection.UpdateOne(
+		ctx,
+		bson.M{"username": username},
+		bson.M{"$set": bson.M{"role": models.RoleAdmin, "updatedAt": now}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount > 0 {
+		log.Printf("User %s is an admin", username)
+		return nil
+	}
+
+	if password == "" {
+		return fmt.Errorf("user %s does not exist and no password was given to create it", username)
+	}
+
+	admin := models.User{
+		Username:  username,
+		Password:  password,
+		Role:      models.RoleAdmin,
+		Scores:    []primitive.ObjectID{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := admin.HashPassword(); err != nil {
+		return err
+	}
+
+	if _, err := collection.InsertOne(ctx, admin); err != nil {
+		return err
+	}
+
+	log.Printf("Created admin user %s", username)
+	return nil
+}
@@ -27,106 +27,146 @@ func InitGameTypes(client *mongo.Client, dbName string) error {
 		return err
 	}
 
-	// Define game types
+	// Define game types. Every seeded game posts values where higher is better,
+	// including the timed ones: typing counts the sentences typed in 60 seconds,
+	// quickmath the correct answers within each round's time limit, and
+	// memorymatch posts 1000 minus penalties for moves and seconds taken.
 	gameTypes := []models.GameType{
 		{
-			GameCode:    "colorguess",
-			Name:        "Color Guess",
-			Description: "Match colors with their correct names",
-			ScoringType: "points",
-			MaxScore:    intPtr(20),
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		},
-		{
-			GameCode:    "guess",
-			Name:        "Number Guess",
-			Description: "Guess a number between 1-100",
-			ScoringType: "points",
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		},
-		{
-			GameCode:    "hangman",
-			Name:        "Hangman",
-			Description: "Guess the word before the hangman is complete",
-			ScoringType: "binary",
-			MaxScore:    intPtr(100),
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		},
-		{
-			GameCode:    "memorymatch",
-			Name:        "Memory Match",
-			Description: "Match pairs of cards",
-			ScoringType: "points",
-			MaxScore:    intPtr(1000),
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		},
-		{
-			GameCode:    "patternrepeater",
-			Name:        "Pattern Repeater",
-			Description: "Repeat patterns of arrow keys",
-			ScoringType: "rounds",
-			MaxScore:    intPtr(20),
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		},
-		{
-			GameCode:    "pong",
-			Name:        "Pong Game",
-			Description: "Classic pong game with bouncing ball",
-			ScoringType: "bounces",
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		},
-		{
-			GameCode:    "quickmath",
-			Name:        "Quick Math Challenge",
-			Description: "Solve math equations quickly",
-			ScoringType: "points",
-			MaxScore:    intPtr(15),
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		},
-		{
-			GameCode:    "simonsays",
-			Name:        "Simon Says",
-			Description: "Repeat color patterns from memory",
-			ScoringType: "rounds",
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		},
-		{
-			GameCode:    "typing",
-			Name:        "Typing Game",
-			Description: "Type sentences as quickly as possible",
-			ScoringType: "sentences",
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-		},
-		{
-			GameCode:    "whackamole",
-			Name:        "Whack-a-Mole",
-			Description: "Click on moles as they appear",
-			ScoringType: "points",
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
+			GameCode:      "colorguess",
+			Name:          "Color Guess",
+			Description:   "Match colors with their correct names",
+			ScoringType:   "points",
+			SortDirection: models.SortDescending,
+			MaxScore:      intPtr(20),
+			MinDuration:   2,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		},
+		{
+			GameCode:      "guess",
+			Name:          "Number Guess",
+			Description:   "Guess a number between 1-100",
+			ScoringType:   "points",
+			SortDirection: models.SortDescending,
+			MinDuration:   1,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		},
+		{
+			GameCode:      "hangman",
+			Name:          "Hangman",
+			Description:   "Guess the word before the hangman is complete",
+			ScoringType:   "binary",
+			SortDirection: models.SortDescending,
+			MaxScore:      intPtr(100),
+			MinDuration:   2,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		},
+		{
+			GameCode:      "memorymatch",
+			Name:          "Memory Match",
+			Description:   "Match pairs of cards",
+			ScoringType:   "points",
+			SortDirection: models.SortDescending,
+			MaxScore:      intPtr(1000),
+			MinDuration:   5,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		},
+		{
+			GameCode:      "patternrepeater",
+			Name:          "Pattern Repeater",
+			Description:   "Repeat patterns of arrow keys",
+			ScoringType:   "rounds",
+			SortDirection: models.SortDescending,
+			MaxScore:      intPtr(20),
+			MinDuration:   2,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		},
+		{
+			GameCode:      "pong",
+			Name:          "Pong Game",
+			Description:   "Classic pong game with bouncing ball",
+			ScoringType:   "bounces",
+			SortDirection: models.SortDescending,
+			MinDuration:   1,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		},
+		{
+			GameCode:      "quickmath",
+			Name:          "Quick Math Challenge",
+			Description:   "Solve math equations quickly",
+			ScoringType:   "points",
+			SortDirection: models.SortDescending,
+			MaxScore:      intPtr(15),
+			MinDuration:   2,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		},
+		{
+			GameCode:      "simonsays",
+			Name:          "Simon Says",
+			Description:   "Repeat color patterns from memory",
+			ScoringType:   "rounds",
+			SortDirection: models.SortDescending,
+			MinDuration:   2,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		},
+		{
+			GameCode:      "typing",
+			Name:          "Typing Game",
+			Description:   "Type sentences as quickly as possible",
+			ScoringType:   "sentences",
+			SortDirection: models.SortDescending,
+			MinDuration:   60,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		},
+		{
+			GameCode:      "whackamole",
+			Name:          "Whack-a-Mole",
+			Description:   "Click on moles as they appear",
+			ScoringType:   "points",
+			SortDirection: models.SortDescending,
+			MinDuration:   30,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
 		},
 	}
 
-	// Insert game types using upsert to avoid duplicates
+	// Insert missing game types only, so that changes made through the admin
+	// API survive a restart
 	for _, gameType := range gameTypes {
+		gameType.MetadataSchema = gameMetadataSchemas[gameType.GameCode]
+
 		filter := bson.M{"game_code": gameType.GameCode}
-		update := bson.M{"$set": gameType}
+		update := bson.M{"$setOnInsert": gameType}
 		opts := options.Update().SetUpsert(true)
-		
+
 		_, err := collection.UpdateOne(context.Background(), filter, update, opts)
 		if err != nil {
 			log.Printf("Error upserting game type %s: %v", gameType.GameCode, err)
 			return err
 		}
+
+		// Set the fields added after the game type was first seeded, where
+		// they are still missing
+		backfills := []fieldBackfill{
+			{"sort_direction", bson.M{"sort_direction": gameType.SortDirection}},
+			{"min_duration", bson.M{"min_duration": gameType.MinDuration}},
+		}
+		if gameType.MetadataSchema != nil {
+			backfills = append(backfills, fieldBackfill{"metadata_schema", bson.M{"metadata_schema": gameType.MetadataSchema}})
+		}
+		if err := applyBackfills(collection, filter, backfills); err != nil {
+			log.Printf("Error backfilling game type %s: %v", gameType.GameCode, err)
+			return err
+		}
 	}
 
 	log.Println("Game types initialized successfully")
@@ -173,3 +213,29 @@ func InitGameScoreIndexes(client *mongo.Client, dbName string) error {
 	log.Println("Game score indexes created successfully")
 	return nil
 }
+
+// InitGameSessionIndexes creates indexes for the game_sessions collection
+func InitGameSessionIndexes(client *mongo.Client, dbName string) error {
+	collection := client.Database(dbName).Collection("game_sessions")
+
+	// Create indexes
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+		{
+			// Expired sessions can no longer be used and are removed automatically
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(context.Background(), indexes)
+	if err != nil {
+		log.Printf("Error creating indexes on game_sessions: %v", err)
+		return err
+	}
+
+	log.Println("Game session indexes created successfully")
+	return nil
+}
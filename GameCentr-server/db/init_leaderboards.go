@@ -0,0 +1,33 @@
+package db
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InitLeaderboardIndexes creates indexes for the materialized leaderboards collection
+func InitLeaderboardIndexes(client *mongo.Client, dbName string) error {
+	collection := client.Database(dbName).Collection("leaderboards")
+
+	// Create indexes
+	indexes := []mongo.IndexModel{
+		{
+			// One leaderboard per game and timeframe
+			Keys:    bson.D{{Key: "game_type", Value: 1}, {Key: "timeframe", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(context.Background(), indexes)
+	if err != nil {
+		log.Printf("Error creating indexes on leaderboards: %v", err)
+		return err
+	}
+
+	log.Println("Leaderboard indexes created successfully")
+	return nil
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InitRatingIndexes creates indexes for the skill ratings collection
+func InitRatingIndexes(client *mongo.Client, dbName string) error {
+	collection := client.Database(dbName).Collection("ratings")
+
+	// Create indexes
+	indexes := []mongo.IndexModel{
+		{
+			// One rating per player and game
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "game_code", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// Rating leaderboards
+			Keys: bson.D{{Key: "game_code", Value: 1}, {Key: "conservative_rating", Value: -1}},
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(context.Background(), indexes)
+	if err != nil {
+		log.Printf("Error creating indexes on ratings: %v", err)
+		return err
+	}
+
+	log.Println("Rating indexes created successfully")
+	return nil
+}
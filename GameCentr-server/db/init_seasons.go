@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InitSeasonIndexes creates indexes for seasons, their archived standings and
+// season-tagged scores
+func InitSeasonIndexes(client *mongo.Client, dbName string) error {
+	database := client.Database(dbName)
+
+	_, err := database.Collection("seasons").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "starts_at", Value: 1}, {Key: "ends_at", Value: 1}},
+	})
+	if err != nil {
+		log.Printf("Error creating indexes on seasons: %v", err)
+		return err
+	}
+
+	// One archived leaderboard per season and game
+	_, err = database.Collection("season_standings").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "season_id", Value: 1}, {Key: "game_code", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Printf("Error creating indexes on season_standings: %v", err)
+		return err
+	}
+
+	// Season leaderboards filter scores by season and game
+	_, err = database.Collection("scores").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "season", Value: 1}, {Key: "game", Value: 1}, {Key: "value", Value: -1}},
+		Options: options.Index().SetSparse(true),
+	})
+	if err != nil {
+		log.Printf("Error creating season index on scores: %v", err)
+		return err
+	}
+
+	log.Println("Season indexes created successfully")
+	return nil
+}
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InitSessionIndexes creates indexes for the sessions collection
+func InitSessionIndexes(client *mongo.Client, dbName string) error {
+	collection := client.Database(dbName).Collection("sessions")
+
+	// Create indexes
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+		{
+			// Expired sessions are removed by MongoDB automatically
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(context.Background(), indexes)
+	if err != nil {
+		log.Printf("Error creating indexes on sessions: %v", err)
+		return err
+	}
+
+	log.Println("Session indexes created successfully")
+	return nil
+}
@@ -3,8 +3,10 @@ package main
 import (
 	"log"
 	"net/http"
+	"netgames-go-server/auth"
 	"netgames-go-server/db"
 	"netgames-go-server/routes"
+	"netgames-go-server/services"
 	"os"
 	"os/signal"
 	"syscall"
@@ -21,6 +23,14 @@ func main() {
 		log.Println("Warning: No .env file found. Using system environment variables.")
 	}
 
+	// Run a maintenance command instead of the server if one was given
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
+	// Load the access token signing key
+	auth.Init()
+
 	// Connect to MongoDB
 	db.ConnectDB()
 	defer db.DisconnectDB()
@@ -48,8 +58,16 @@ func main() {
 	// Setup routes
 	routes.SetupGameScoreRoutes(router)
 	routes.SetupUserRoutes(router)
+	routes.SetupAuthRoutes(router)
 	routes.SetupAchievementRoutes(router)
 	routes.SetupScoreRoutes(router)
+	routes.SetupSeasonRoutes(router)
+
+	// Keep the materialized leaderboards fresh for rolling timeframes
+	services.StartLeaderboardScheduler(5 * time.Minute)
+
+	// Freeze the final standings of seasons once they end
+	services.StartSeasonScheduler(5 * time.Minute)
 
 	// Get port from environment variable or use default
 	port := os.Getenv("PORT")
@@ -8,16 +8,84 @@ import (
 
 // Achievement represents a game achievement that can be earned by users
 type Achievement struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	GameCode    string             `bson:"game_code" json:"gameCode"`
-	Code        string             `bson:"code" json:"code"`
-	Title       string             `bson:"title" json:"title"`
-	Description string             `bson:"description" json:"description"`
-	Icon        string             `bson:"icon" json:"icon"`
-	Difficulty  string             `bson:"difficulty" json:"difficulty"` // easy, medium, hard
-	IsHidden    bool               `bson:"is_hidden" json:"isHidden"`    // Easter egg achievements
-	CreatedAt   time.Time          `bson:"created_at" json:"createdAt"`
-	UpdatedAt   time.Time          `bson:"updated_at" json:"updatedAt"`
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GameCode      string             `bson:"game_code" json:"gameCode"`
+	Code          string             `bson:"code" json:"code"`
+	Title         string             `bson:"title" json:"title"`
+	Description   string             `bson:"description" json:"description"`
+	Icon          string             `bson:"icon" json:"icon"`
+	Difficulty    string             `bson:"difficulty" json:"difficulty"`                           // easy, medium, hard
+	IsHidden      bool               `bson:"is_hidden" json:"isHidden"`                              // Easter egg achievements
+	Criteria      *Criteria          `bson:"criteria,omitempty" json:"criteria,omitempty"`           // Rule that unlocks the achievement, nil for manually awarded ones
+	Points        int                `bson:"points,omitempty" json:"points"`                         // Defaults to the difficulty's points when zero
+	TierGroup     string             `bson:"tier_group,omitempty" json:"tierGroup,omitempty"`        // Achievements sharing a tier group are the tiers of one achievement
+	Tier          int                `bson:"tier,omitempty" json:"tier,omitempty"`                   // Position within the tier group, see TierBronze
+	Prerequisites []string           `bson:"prerequisites,omitempty" json:"prerequisites,omitempty"` // Codes of achievements of the same game to unlock first
+	SortOrder     int                `bson:"sort_order" json:"sortOrder"`                            // Position in achievement listings, lowest first
+	Retired       bool               `bson:"retired" json:"retired"`                                 // Retired achievements can no longer be unlocked
+	CreatedAt     time.Time          `bson:"created_at" json:"createdAt"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updatedAt"`
+	SeedVersion   int                `bson:"seed_version,omitempty" json:"-"`  // Version of the seeds that last migrated it, zero when created through the admin API
+	UnlockPercent *float64           `bson:"-" json:"unlockPercent,omitempty"` // Share of active users who unlocked it, computed on read
+}
+
+// Achievement difficulties
+const (
+	DifficultyEasy   = "easy"
+	DifficultyMedium = "medium"
+	DifficultyHard   = "hard"
+)
+
+// DifficultyPoints are the default points of an achievement of each difficulty
+var DifficultyPoints = map[string]int{
+	DifficultyEasy:   10,
+	DifficultyMedium: 25,
+	DifficultyHard:   50,
+}
+
+// PointValue returns the points the achievement is worth
+func (a *Achievement) PointValue() int {
+	if a.Points > 0 {
+		return a.Points
+	}
+	return DifficultyPoints[a.Difficulty]
+}
+
+// Achievement tiers, from the lowest to the highest
+const (
+	TierBronze = 1
+	TierSilver = 2
+	TierGold   = 3
+)
+
+// TierNames are the display names of the achievement tiers
+var TierNames = map[int]string{
+	TierBronze: "bronze",
+	TierSilver: "silver",
+	TierGold:   "gold",
+}
+
+// Criteria operators comparing a fact with a value
+const (
+	OpEq      = "eq"
+	OpNe      = "ne"
+	OpGt      = "gt"
+	OpGte     = "gte"
+	OpLt      = "lt"
+	OpLte     = "lte"
+	OpSizeGte = "size_gte" // Number of entries in an object or array fact
+)
+
+// Criteria is a declarative achievement rule. A node either combines other
+// nodes with All, Any or Not, or compares the fact at Field with Value using Op.
+// Fields are dotted paths into the facts, e.g. "completed" or "server.hour".
+type Criteria struct {
+	All   []Criteria  `bson:"all,omitempty" json:"all,omitempty"`
+	Any   []Criteria  `bson:"any,omitempty" json:"any,omitempty"`
+	Not   *Criteria   `bson:"not,omitempty" json:"not,omitempty"`
+	Field string      `bson:"field,omitempty" json:"field,omitempty"`
+	Op    string      `bson:"op,omitempty" json:"op,omitempty"`
+	Value interface{} `bson:"value,omitempty" json:"value,omitempty"`
 }
 
 // UserAchievement represents an achievement earned by a user
@@ -29,24 +97,96 @@ type UserAchievement struct {
 	AwardedAt     time.Time          `bson:"awarded_at" json:"awardedAt"`
 }
 
+// Achievement audit actions
+const (
+	AuditAwarded = "awarded"
+	AuditRevoked = "revoked"
+)
+
+// Sources of an achievement award or revocation
+const (
+	AuditSourceRule   = "rule"   // Unlocked by the achievement's criteria
+	AuditSourceManual = "manual" // Awarded or revoked by an admin
+	AuditSourceImport = "import" // Carried over from another system
+)
+
+// AchievementAudit records a single award or revocation. Audit entries are
+// only ever inserted, never changed or deleted.
+type AchievementAudit struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID        primitive.ObjectID  `bson:"user_id" json:"userId"`
+	AchievementID primitive.ObjectID  `bson:"achievement_id" json:"achievementId"`
+	GameCode      string              `bson:"game_code" json:"gameCode"`
+	Code          string              `bson:"code" json:"code"`
+	Action        string              `bson:"action" json:"action"`
+	Source        string              `bson:"source" json:"source"`
+	ActorID       *primitive.ObjectID `bson:"actor_id,omitempty" json:"actorId,omitempty"` // Admin who acted, nil for rule awards
+	Reason        string              `bson:"reason,omitempty" json:"reason,omitempty"`
+	CreatedAt     time.Time           `bson:"created_at" json:"createdAt"`
+}
+
+// RevokedAchievement marks an achievement an admin revoked from a user. Rules
+// do not award it again until an admin awards it manually.
+type RevokedAchievement struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID        primitive.ObjectID `bson:"user_id" json:"userId"`
+	AchievementID primitive.ObjectID `bson:"achievement_id" json:"achievementId"`
+	RevokedBy     primitive.ObjectID `bson:"revoked_by" json:"revokedBy"`
+	Reason        string             `bson:"reason,omitempty" json:"reason,omitempty"`
+	RevokedAt     time.Time          `bson:"revoked_at" json:"revokedAt"`
+}
+
 // AchievementWithDetails represents an achievement with additional details for display
 type AchievementWithDetails struct {
+	ID            primitive.ObjectID `json:"id"`
+	GameCode      string             `json:"gameCode"`
+	GameName      string             `json:"gameName"`
+	Code          string             `json:"code"`
+	Title         string             `json:"title"`
+	Description   string             `json:"description"`
+	Icon          string             `json:"icon"`
+	Difficulty    string             `json:"difficulty"`
+	IsHidden      bool               `json:"isHidden"`
+	IsUnlocked    bool               `json:"isUnlocked"`
+	AwardedAt     *time.Time         `json:"awardedAt,omitempty"`
+	Points        int                `json:"points"`
+	UnlockPercent *float64           `json:"unlockPercent,omitempty"` // Share of active users who unlocked it
+	Progress      *float64           `json:"progress,omitempty"`      // Counter value so far, for counter achievements
+	Target        *float64           `json:"target,omitempty"`        // Counter value that unlocks the achievement
+	Percent       *float64           `json:"percent,omitempty"`       // Progress towards the target from 0 to 100
+	TierGroup     string             `json:"tierGroup,omitempty"`
+	Tier          int                `json:"tier,omitempty"`     // Highest unlocked tier of a tier group
+	TierName      string             `json:"tierName,omitempty"` // Name of Tier
+	Tiers         []AchievementTier  `json:"tiers,omitempty"`    // Every tier of a tier group, lowest first
+}
+
+// AchievementTier is one tier of a tiered achievement
+type AchievementTier struct {
 	ID          primitive.ObjectID `json:"id"`
-	GameCode    string             `json:"gameCode"`
-	GameName    string             `json:"gameName"`
 	Code        string             `json:"code"`
 	Title       string             `json:"title"`
 	Description string             `json:"description"`
-	Icon        string             `json:"icon"`
-	Difficulty  string             `json:"difficulty"`
-	IsHidden    bool               `json:"isHidden"`
+	Tier        int                `json:"tier"`
+	TierName    string             `json:"tierName"`
+	Points      int                `json:"points"`
 	IsUnlocked  bool               `json:"isUnlocked"`
 	AwardedAt   *time.Time         `json:"awardedAt,omitempty"`
 }
 
+// AchievementProgress is a user's progress towards a counter achievement.
+// Progress only ever increases.
+type AchievementProgress struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID        primitive.ObjectID `bson:"user_id" json:"userId"`
+	AchievementID primitive.ObjectID `bson:"achievement_id" json:"achievementId"`
+	Progress      float64            `bson:"progress" json:"progress"`
+	Target        float64            `bson:"target" json:"target"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updatedAt"`
+}
+
 // UserProfile represents a user's profile with their achievements
 type UserProfile struct {
-	User         User                   `json:"user"`
+	User         UserResponse             `json:"user"`
 	Achievements []AchievementWithDetails `json:"achievements"`
-	Stats        map[string]interface{} `json:"stats"`
+	Stats        ProfileStats             `json:"stats"`
 }
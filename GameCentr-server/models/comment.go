@@ -6,14 +6,24 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// Comment represents a user's comment on a score
+// Comment represents a user's comment on a score. Deleted comments are kept
+// as tombstones without text, and edits keep the replaced versions.
 type Comment struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
-	Score     primitive.ObjectID `bson:"score" json:"score" binding:"required"`
-	Author    primitive.ObjectID `bson:"author" json:"author" binding:"required"`
-	Text      string             `bson:"text" json:"text" binding:"required"`
-	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
-	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"_id,omitempty"`
+	Score     primitive.ObjectID  `bson:"score" json:"score" binding:"required"`
+	Author    primitive.ObjectID  `bson:"author" json:"author" binding:"required"`
+	Text      string              `bson:"text" json:"text" binding:"required"`
+	Edits     []CommentEdit       `bson:"edits,omitempty" json:"edits,omitempty"` // Previous versions of the text, oldest first
+	DeletedAt *time.Time          `bson:"deletedAt,omitempty" json:"deletedAt,omitempty"`
+	DeletedBy *primitive.ObjectID `bson:"deletedBy,omitempty" json:"deletedBy,omitempty"` // The author or a moderator
+	CreatedAt time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time           `bson:"updatedAt" json:"updatedAt"`
+}
+
+// CommentEdit is a version of a comment's text that was replaced
+type CommentEdit struct {
+	Text     string    `bson:"text" json:"text"`
+	EditedAt time.Time `bson:"editedAt" json:"editedAt"` // When the text was replaced
 }
 
 // CommentWithUserDetails includes user information with the comment
@@ -22,6 +32,7 @@ type CommentWithUserDetails struct {
 	Score     primitive.ObjectID `json:"score"`
 	Author    UserResponse       `json:"author"`
 	Text      string             `json:"text"`
+	Edited    bool               `json:"edited"`
 	CreatedAt time.Time          `json:"createdAt"`
 	UpdatedAt time.Time          `json:"updatedAt"`
 }
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GameSession is a server-issued ticket for a single play of a game.
+// A score can only be submitted against an unused session of the same user and game.
+type GameSession struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID  `bson:"user_id" json:"userId"`
+	GameCode  string              `bson:"game_code" json:"gameCode"`
+	Seed      int64               `bson:"seed" json:"seed"`
+	IssuedAt  time.Time           `bson:"issued_at" json:"issuedAt"`
+	ExpiresAt time.Time           `bson:"expires_at" json:"expiresAt"`
+	UsedAt    *time.Time          `bson:"used_at,omitempty" json:"usedAt,omitempty"`
+	ScoreID   *primitive.ObjectID `bson:"score_id,omitempty" json:"scoreId,omitempty"`
+}
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Metadata property types, named after their JSON Schema counterparts
+const (
+	SchemaInteger = "integer"
+	SchemaNumber  = "number"
+	SchemaBoolean = "boolean"
+	SchemaString  = "string"
+	SchemaObject  = "object"
+	SchemaArray   = "array"
+)
+
+// MetadataSchema describes the Score.Metadata a game accepts. It is a subset of
+// JSON Schema, so it can be published to client authors as-is.
+type MetadataSchema struct {
+	Type                 string                      `bson:"type" json:"type"`
+	Properties           map[string]MetadataProperty `bson:"properties" json:"properties"`
+	Required             []string                    `bson:"required,omitempty" json:"required,omitempty"`
+	AdditionalProperties bool                        `bson:"additional_properties" json:"additionalProperties"`
+}
+
+// MetadataProperty describes a single metadata field
+type MetadataProperty struct {
+	Type        string   `bson:"type" json:"type"`
+	Description string   `bson:"description,omitempty" json:"description,omitempty"`
+	Minimum     *float64 `bson:"minimum,omitempty" json:"minimum,omitempty"`
+	Maximum     *float64 `bson:"maximum,omitempty" json:"maximum,omitempty"`
+}
+
+// ValidateMetadata checks score metadata against the game's metadata schema.
+// Games without a schema accept any metadata.
+func (g *GameType) ValidateMetadata(metadata map[string]interface{}) []FieldError {
+	if g.MetadataSchema == nil {
+		return nil
+	}
+	return g.MetadataSchema.Validate(metadata)
+}
+
+// Validate returns one error per metadata field that does not match the schema
+func (s *MetadataSchema) Validate(metadata map[string]interface{}) []FieldError {
+	var errs []FieldError
+
+	for _, name := range s.Required {
+		if _, ok := metadata[name]; !ok {
+			errs = append(errs, FieldError{
+				Field:   "metadata." + name,
+				Code:    "required",
+				Message: name + " is required",
+			})
+		}
+	}
+
+	// Walk fields in a stable order so responses are deterministic
+	names := make([]string, 0, len(metadata))
+	for name := range metadata {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, declared := s.Properties[name]
+		if !declared {
+			if !s.AdditionalProperties {
+				errs = append(errs, FieldError{
+					Field:   "metadata." + name,
+					Code:    "unknown_field",
+					Message: name + " is not an accepted metadata field",
+				})
+			}
+			continue
+		}
+		if err := property.validate(name, metadata[name]); err != nil {
+			errs = append(errs, *err)
+		}
+	}
+
+	return errs
+}
+
+// validate checks a single value against the property definition
+func (p MetadataProperty) validate(name string, value interface{}) *FieldError {
+	field := "metadata." + name
+
+	if !matchesSchemaType(p.Type, value) {
+		return &FieldError{
+			Field:   field,
+			Code:    "invalid_type",
+			Message: fmt.Sprintf("%s must be of type %s", name, p.Type),
+		}
+	}
+
+	if number, ok := toFloat(value); ok {
+		if p.Minimum != nil && number < *p.Minimum {
+			return &FieldError{
+				Field:   field,
+				Code:    "below_minimum",
+				Message: fmt.Sprintf("%s must be at least %v", name, *p.Minimum),
+			}
+		}
+		if p.Maximum != nil && number > *p.Maximum {
+			return &FieldError{
+				Field:   field,
+				Code:    "above_maximum",
+				Message: fmt.Sprintf("%s must be at most %v", name, *p.Maximum),
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchesSchemaType reports whether a decoded JSON value has the given schema type
+func matchesSchemaType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case SchemaInteger:
+		number, ok := toFloat(value)
+		return ok && number == math.Trunc(number)
+	case SchemaNumber:
+		_, ok := toFloat(value)
+		return ok
+	case SchemaBoolean:
+		_, ok := value.(bool)
+		return ok
+	case SchemaString:
+		_, ok := value.(string)
+		return ok
+	case SchemaObject:
+		_, ok := value.(map[string]interface{})
+		return ok
+	case SchemaArray:
+		_, ok := value.([]interface{})
+		return ok
+	}
+	return false
+}
+
+// toFloat converts any numeric value decoded from JSON or BSON to float64
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
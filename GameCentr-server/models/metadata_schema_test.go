@@ -0,0 +1,69 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func TestValidateMetadata(t *testing.T) {
+	schema := &MetadataSchema{
+		Type: SchemaObject,
+		Properties: map[string]MetadataProperty{
+			"moves":     {Type: SchemaInteger, Minimum: floatPtr(0), Maximum: floatPtr(100)},
+			"timeSpent": {Type: SchemaNumber, Minimum: floatPtr(0)},
+			"completed": {Type: SchemaBoolean},
+			"word":      {Type: SchemaString},
+			"range":     {Type: SchemaObject},
+			"guesses":   {Type: SchemaArray},
+		},
+		Required: []string{"moves"},
+	}
+
+	tests := []struct {
+		name     string
+		schema   *MetadataSchema
+		metadata map[string]interface{}
+		want     []string
+	}{
+		{"no schema accepts anything", nil, map[string]interface{}{"anything": "goes"}, nil},
+		{"valid metadata", schema, map[string]interface{}{
+			"moves":     float64(12),
+			"timeSpent": 3.5,
+			"completed": true,
+			"word":      "gopher",
+			"range":     map[string]interface{}{"min": 1},
+			"guesses":   []interface{}{1, 2},
+		}, nil},
+		{"integer from BSON", schema, map[string]interface{}{"moves": int32(12)}, nil},
+		{"missing required field", schema, map[string]interface{}{"timeSpent": 1.0}, []string{"required"}},
+		{"fractional integer", schema, map[string]interface{}{"moves": 1.5}, []string{"invalid_type"}},
+		{"string for number", schema, map[string]interface{}{"moves": 1.0, "timeSpent": "fast"}, []string{"invalid_type"}},
+		{"below minimum", schema, map[string]interface{}{"moves": -1.0}, []string{"below_minimum"}},
+		{"above maximum", schema, map[string]interface{}{"moves": 101.0}, []string{"above_maximum"}},
+		{"unknown field rejected", schema, map[string]interface{}{"moves": 1.0, "extra": 1.0}, []string{"unknown_field"}},
+		{"errors in field order", schema, map[string]interface{}{"word": 1.0, "completed": "yes"}, []string{"required", "invalid_type", "invalid_type"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gameType := GameType{MetadataSchema: tt.schema}
+			got := errorCodes(gameType.ValidateMetadata(tt.metadata))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ValidateMetadata(%v) = %v, want %v", tt.metadata, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("additional properties allowed", func(t *testing.T) {
+		open := *schema
+		open.AdditionalProperties = true
+		gameType := GameType{MetadataSchema: &open}
+		if errs := gameType.ValidateMetadata(map[string]interface{}{"moves": 1.0, "extra": 1.0}); len(errs) != 0 {
+			t.Errorf("ValidateMetadata with an extra field = %v, want no errors", errs)
+		}
+	})
+}
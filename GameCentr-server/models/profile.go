@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// ProfileStats summarizes a user's play history for their profile
+type ProfileStats struct {
+	TotalGamesPlayed  int         `json:"totalGamesPlayed"`
+	TotalAchievements int64       `json:"totalAchievements"`
+	AchievementScore  int         `json:"achievementScore"`
+	FavoriteGame      string      `json:"favoriteGame"`  // Game code with the most plays
+	HighestScore      int         `json:"highestScore"`  // Best score over higher-is-better games
+	CurrentStreak     int         `json:"currentStreak"` // Consecutive UTC days played, ending today or yesterday
+	LongestStreak     int         `json:"longestStreak"`
+	FirstPlayedAt     *time.Time  `json:"firstPlayedAt"`
+	LastPlayedAt      *time.Time  `json:"lastPlayedAt"`
+	Games             []GameStats `json:"games"`
+}
+
+// GameStats summarizes a user's plays of a single game
+type GameStats struct {
+	GameCode      string    `json:"gameCode"`
+	GameName      string    `json:"gameName"`
+	Plays         int       `json:"plays"`
+	BestScore     int       `json:"bestScore"` // Respects the game's sort order
+	FirstPlayedAt time.Time `json:"firstPlayedAt"`
+	LastPlayedAt  time.Time `json:"lastPlayedAt"`
+}
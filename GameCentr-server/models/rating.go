@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Rating is a player's Glicko skill rating in one game
+type Rating struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	UserID       primitive.ObjectID `bson:"user_id" json:"user_id"`
+	GameCode     string             `bson:"game_code" json:"game_code"`
+	Rating       float64            `bson:"rating" json:"rating"`
+	Deviation    float64            `bson:"deviation" json:"deviation"`                     // Uncertainty of the rating, shrinks as the player plays more
+	Conservative float64            `bson:"conservative_rating" json:"conservative_rating"` // Rating minus twice the deviation, used for ranking
+	Plays        int                `bson:"plays" json:"plays"`
+	LastPlayedAt time.Time          `bson:"last_played_at" json:"last_played_at"`
+	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
+	RecomputedAt time.Time          `bson:"recomputed_at,omitempty" json:"-"` // Start of the recompute that wrote it, which replayed every score posted before
+}
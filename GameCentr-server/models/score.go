@@ -7,15 +7,17 @@ import (
 )
 
 type Score struct {
-	ID        primitive.ObjectID   `bson:"_id,omitempty" json:"_id,omitempty"`
-	Owner     primitive.ObjectID   `bson:"owner" json:"owner" binding:"required"`
-	Game      string               `bson:"game" json:"game" binding:"required"` // Game type identifier
-	Value     int                  `bson:"value" json:"value" binding:"required"`
-	Text      string               `bson:"text" json:"text"`
+	ID        primitive.ObjectID     `bson:"_id,omitempty" json:"_id,omitempty"`
+	Owner     primitive.ObjectID     `bson:"owner" json:"owner" binding:"required"`
+	Game      string                 `bson:"game" json:"game" binding:"required"` // Game type identifier
+	Value     int                    `bson:"value" json:"value" binding:"required"`
+	Text      string                 `bson:"text" json:"text"`
 	Metadata  map[string]interface{} `bson:"metadata,omitempty" json:"metadata,omitempty"` // Additional game-specific data
-	Comments  []primitive.ObjectID `bson:"comments" json:"comments"`
-	CreatedAt time.Time            `bson:"createdAt" json:"createdAt"`
-	UpdatedAt time.Time            `bson:"updatedAt" json:"updatedAt"`
+	Session   primitive.ObjectID     `bson:"session,omitempty" json:"session,omitempty"`   // Game session the score was submitted against
+	Season    primitive.ObjectID     `bson:"season,omitempty" json:"season,omitempty"`     // Season that was active when the score was posted
+	Comments  []primitive.ObjectID   `bson:"comments" json:"comments"`
+	CreatedAt time.Time              `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time              `bson:"updatedAt" json:"updatedAt"`
 }
 
 // ScoreWithUserDetails includes user information along with the score
@@ -33,14 +35,50 @@ type ScoreWithUserDetails struct {
 
 // GameType represents a type of game in the system
 type GameType struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
-	GameCode    string             `bson:"game_code" json:"game_code" binding:"required"`
-	Name        string             `bson:"name" json:"name" binding:"required"`
-	Description string             `bson:"description" json:"description"`
-	ScoringType string             `bson:"scoring_type" json:"scoring_type"`
-	MaxScore    *int               `bson:"max_score,omitempty" json:"max_score,omitempty"`
-	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
-	UpdatedAt   time.Time          `bson:"updatedAt" json:"updatedAt"`
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	GameCode       string             `bson:"game_code" json:"game_code" binding:"required"`
+	Name           string             `bson:"name" json:"name" binding:"required"`
+	Description    string             `bson:"description" json:"description"`
+	ScoringType    string             `bson:"scoring_type" json:"scoring_type"`
+	MaxScore       *int               `bson:"max_score,omitempty" json:"max_score,omitempty"`
+	SortDirection  string             `bson:"sort_direction" json:"sort_direction"` // desc when higher scores are better, asc when lower scores are better
+	MinDuration    int                `bson:"min_duration" json:"min_duration"`     // Shortest plausible play in seconds
+	MetadataSchema *MetadataSchema    `bson:"metadata_schema,omitempty" json:"metadata_schema,omitempty"`
+	Disabled       bool               `bson:"disabled" json:"disabled"` // Disabled games are hidden and accept no new scores
+	CreatedAt      time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Sort directions a game can rank its scores in
+const (
+	SortDescending = "desc" // Higher scores are better
+	SortAscending  = "asc"  // Lower scores are better, e.g. completion times
+)
+
+// LowerIsBetter reports whether the game ranks lower scores first
+func (g *GameType) LowerIsBetter() bool {
+	return g.SortDirection == SortAscending
+}
+
+// SortOrder returns the MongoDB sort order that puts the best score first
+func (g *GameType) SortOrder() int {
+	if g.LowerIsBetter() {
+		return 1
+	}
+	return -1
+}
+
+// IsValidSortDirection reports whether direction is one of the known sort directions
+func IsValidSortDirection(direction string) bool {
+	return direction == SortDescending || direction == SortAscending
+}
+
+// IsBetter reports whether score a ranks above score b in this game
+func (g *GameType) IsBetter(a, b int) bool {
+	if g.LowerIsBetter() {
+		return a < b
+	}
+	return a > b
 }
 
 // Leaderboard represents a cached leaderboard for a specific game and timeframe
@@ -54,8 +92,11 @@ type Leaderboard struct {
 
 // LeaderboardEntry represents a single entry in a leaderboard
 type LeaderboardEntry struct {
-	UserID   primitive.ObjectID `bson:"user_id" json:"user_id"`
-	Username string             `bson:"username" json:"username"`
-	Score    int                `bson:"score" json:"score"`
-	Rank     int                `bson:"rank" json:"rank"`
+	ScoreID   primitive.ObjectID     `bson:"score_id" json:"score_id"`
+	UserID    primitive.ObjectID     `bson:"user_id" json:"user_id"`
+	Username  string                 `bson:"username" json:"username"`
+	Score     int                    `bson:"score" json:"score"`
+	Rank      int                    `bson:"rank" json:"rank"`
+	Metadata  map[string]interface{} `bson:"metadata,omitempty" json:"metadata,omitempty"`
+	CreatedAt time.Time              `bson:"created_at" json:"created_at"`
 }
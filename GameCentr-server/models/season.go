@@ -0,0 +1,58 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Season states derived from the season's dates
+const (
+	SeasonUpcoming = "upcoming"
+	SeasonActive   = "active"
+	SeasonEnded    = "ended"    // Ended but the standings are not archived yet
+	SeasonArchived = "archived" // Final standings are frozen in season_standings
+)
+
+// Season is a competition period. Scores posted while a season is active are
+// tagged with it and ranked on the season's own leaderboards.
+type Season struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	Name        string             `bson:"name" json:"name"`
+	Description string             `bson:"description" json:"description"`
+	StartsAt    time.Time          `bson:"starts_at" json:"starts_at"`
+	EndsAt      time.Time          `bson:"ends_at" json:"ends_at"`
+	GameCodes   []string           `bson:"game_codes" json:"game_codes"` // Games included in the season, empty for all games
+	ArchivedAt  *time.Time         `bson:"archived_at,omitempty" json:"archived_at,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// SeasonStanding is the archived final leaderboard of a game in a season
+type SeasonStanding struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	SeasonID     primitive.ObjectID `bson:"season_id" json:"season_id"`
+	GameCode     string             `bson:"game_code" json:"game_code"`
+	Entries      []LeaderboardEntry `bson:"entries" json:"entries"`
+	TotalPlayers int64              `bson:"total_players" json:"total_players"`
+	ArchivedAt   time.Time          `bson:"archived_at" json:"archived_at"`
+}
+
+// Status returns whether the season is upcoming, active, ended or archived at now
+func (s *Season) Status(now time.Time) string {
+	switch {
+	case s.ArchivedAt != nil:
+		return SeasonArchived
+	case now.Before(s.StartsAt):
+		return SeasonUpcoming
+	case now.Before(s.EndsAt):
+		return SeasonActive
+	}
+	return SeasonEnded
+}
+
+// IncludesGame reports whether scores of the game count towards the season
+func (s *Season) IncludesGame(gameCode string) bool {
+	return len(s.GameCodes) == 0 || slices.Contains(s.GameCodes, gameCode)
+}
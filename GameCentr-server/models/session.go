@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Session represents a login session and its current refresh token.
+// Every rotation moves the previous token hash into RotatedHashes so that a
+// replayed token can be recognised and the whole session revoked.
+type Session struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID           primitive.ObjectID `bson:"user_id" json:"userId"`
+	RefreshTokenHash string             `bson:"refresh_token_hash" json:"-"`
+	RotatedHashes    []string           `bson:"rotated_hashes" json:"-"`
+	UserAgent        string             `bson:"user_agent" json:"userAgent"`
+	IPAddress        string             `bson:"ip_address" json:"ipAddress"`
+	CreatedAt        time.Time          `bson:"created_at" json:"createdAt"`
+	LastUsedAt       time.Time          `bson:"last_used_at" json:"lastUsedAt"`
+	ExpiresAt        time.Time          `bson:"expires_at" json:"expiresAt"`
+	RevokedAt        *time.Time         `bson:"revoked_at,omitempty" json:"revokedAt,omitempty"`
+	RevokedReason    string             `bson:"revoked_reason,omitempty" json:"revokedReason,omitempty"`
+}
+
+// IsActive reports whether the session can still be used at the given time
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
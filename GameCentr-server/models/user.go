@@ -7,11 +7,19 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// User roles, from least to most privileged
+const (
+	RolePlayer    = "player"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
 // User represents a user in the system
 type User struct {
 	ID        primitive.ObjectID   `bson:"_id,omitempty" json:"_id,omitempty"`
 	Username  string               `bson:"username" json:"username" binding:"required"`
 	Password  string               `bson:"password" json:"password" binding:"required"`
+	Role      string               `bson:"role" json:"role"`
 	Scores    []primitive.ObjectID `bson:"scores" json:"scores"`
 	CreatedAt time.Time            `bson:"createdAt" json:"createdAt"`
 	UpdatedAt time.Time            `bson:"updatedAt" json:"updatedAt"`
@@ -21,6 +29,7 @@ type User struct {
 type UserResponse struct {
 	ID        primitive.ObjectID   `json:"_id,omitempty"`
 	Username  string               `json:"username"`
+	Role      string               `json:"role"`
 	Scores    []primitive.ObjectID `json:"scores"`
 	CreatedAt time.Time            `json:"createdAt"`
 	UpdatedAt time.Time            `json:"updatedAt"`
@@ -47,8 +56,26 @@ func (u *User) ToResponse() UserResponse {
 	return UserResponse{
 		ID:        u.ID,
 		Username:  u.Username,
+		Role:      u.EffectiveRole(),
 		Scores:    u.Scores,
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
 	}
 }
+
+// EffectiveRole returns the user's role, treating users created before roles existed as players
+func (u *User) EffectiveRole() string {
+	if u.Role == "" {
+		return RolePlayer
+	}
+	return u.Role
+}
+
+// IsValidRole reports whether role is one of the known user roles
+func IsValidRole(role string) bool {
+	switch role {
+	case RolePlayer, RoleModerator, RoleAdmin:
+		return true
+	}
+	return false
+}
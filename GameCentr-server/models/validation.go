@@ -0,0 +1,65 @@
+package models
+
+import "fmt"
+
+// Scoring types a game can use
+const (
+	ScoringPoints    = "points"    // Any value between 0 and MaxScore
+	ScoringBinary    = "binary"    // Either 0 (loss) or MaxScore (win)
+	ScoringRounds    = "rounds"    // Number of rounds survived
+	ScoringBounces   = "bounces"   // Number of bounces kept in play
+	ScoringSentences = "sentences" // Number of sentences typed
+)
+
+// FieldError describes why a single request field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// IsValidScoringType reports whether scoringType is one of the known scoring types
+func IsValidScoringType(scoringType string) bool {
+	switch scoringType {
+	case ScoringPoints, ScoringBinary, ScoringRounds, ScoringBounces, ScoringSentences:
+		return true
+	}
+	return false
+}
+
+// ValidateScore checks a score value against the game's scoring rules
+func (g *GameType) ValidateScore(value int) []FieldError {
+	var errs []FieldError
+
+	switch g.ScoringType {
+	case ScoringBinary:
+		winValue := 1
+		if g.MaxScore != nil {
+			winValue = *g.MaxScore
+		}
+		if value != 0 && value != winValue {
+			errs = append(errs, FieldError{
+				Field:   "value",
+				Code:    "invalid_binary_value",
+				Message: fmt.Sprintf("value must be 0 or %d for %s", winValue, g.Name),
+			})
+		}
+	default:
+		// points, rounds, bounces and sentences are all counts bounded by MaxScore
+		if value < 0 {
+			errs = append(errs, FieldError{
+				Field:   "value",
+				Code:    "negative_value",
+				Message: "value must not be negative",
+			})
+		} else if g.MaxScore != nil && value > *g.MaxScore {
+			errs = append(errs, FieldError{
+				Field:   "value",
+				Code:    "above_max_score",
+				Message: fmt.Sprintf("value must not exceed %d for %s", *g.MaxScore, g.Name),
+			})
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// errorCodes returns the codes of the field errors, in order
+func errorCodes(errs []FieldError) []string {
+	codes := make([]string, 0, len(errs))
+	for _, err := range errs {
+		codes = append(codes, err.Code)
+	}
+	return codes
+}
+
+func TestValidateScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		gameType GameType
+		value    int
+		want     []string
+	}{
+		{"points within max", GameType{ScoringType: ScoringPoints, MaxScore: intPtr(100)}, 100, nil},
+		{"points above max", GameType{ScoringType: ScoringPoints, MaxScore: intPtr(100)}, 101, []string{"above_max_score"}},
+		{"points without max", GameType{ScoringType: ScoringPoints}, 1000000, nil},
+		{"negative points", GameType{ScoringType: ScoringPoints}, -1, []string{"negative_value"}},
+		{"negative rounds", GameType{ScoringType: ScoringRounds, MaxScore: intPtr(10)}, -5, []string{"negative_value"}},
+		{"zero sentences", GameType{ScoringType: ScoringSentences}, 0, nil},
+		{"binary loss", GameType{ScoringType: ScoringBinary, MaxScore: intPtr(10)}, 0, nil},
+		{"binary win", GameType{ScoringType: ScoringBinary, MaxScore: intPtr(10)}, 10, nil},
+		{"binary partial", GameType{ScoringType: ScoringBinary, MaxScore: intPtr(10)}, 5, []string{"invalid_binary_value"}},
+		{"binary win defaults to one", GameType{ScoringType: ScoringBinary}, 1, nil},
+		{"binary above default win", GameType{ScoringType: ScoringBinary}, 2, []string{"invalid_binary_value"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := errorCodes(tt.gameType.ValidateScore(tt.value))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ValidateScore(%d) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"netgames-go-server/auth"
 	"netgames-go-server/controllers"
 
 	"github.com/gin-gonic/gin"
@@ -16,10 +17,20 @@ func SetupAchievementRoutes(router *gin.Engine) {
 		// Get user achievements
 		achievementRoutes.GET("/user/:userId", controllers.GetUserAchievements)
 		
-		// Award an achievement
-		achievementRoutes.POST("/award", controllers.AwardAchievement)
+		// Award an achievement (admin only)
+		achievementRoutes.POST("/award", RequireAuth(), RequirePermission(auth.PermAwardAchievements), controllers.AwardAchievement)
 		
+		// Revoke one or all of a user's achievements (admin only)
+		achievementRoutes.POST("/revoke", RequireAuth(), RequirePermission(auth.PermAwardAchievements), controllers.RevokeAchievement)
+
 		// Check achievement progress
-		achievementRoutes.POST("/check-progress", controllers.CheckAchievementProgress)
+		achievementRoutes.POST("/check-progress", RequireAuth(), controllers.CheckAchievementProgress)
+
+		// Manage achievements (admin only)
+		achievementRoutes.POST("", RequireAuth(), RequirePermission(auth.PermManageAchievements), controllers.CreateAchievement)
+		achievementRoutes.PUT("/order", RequireAuth(), RequirePermission(auth.PermManageAchievements), controllers.ReorderAchievements)
+		achievementRoutes.PUT("/:achievementId", RequireAuth(), RequirePermission(auth.PermManageAchievements), controllers.UpdateAchievement)
+		achievementRoutes.POST("/:achievementId/retire", RequireAuth(), RequirePermission(auth.PermManageAchievements), controllers.RetireAchievement)
+		achievementRoutes.POST("/:achievementId/restore", RequireAuth(), RequirePermission(auth.PermManageAchievements), controllers.RestoreAchievement)
 	}
 }
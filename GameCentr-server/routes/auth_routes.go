@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"netgames-go-server/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAuthRoutes configures all routes related to sessions and tokens
+func SetupAuthRoutes(router *gin.Engine) {
+	authGroup := router.Group("/auth")
+	{
+		// Exchange a refresh token for new tokens
+		authGroup.POST("/refresh", controllers.RefreshSession)
+
+		// Log out of the current session
+		authGroup.POST("/logout", RequireAuth(), controllers.Logout)
+
+		// Log out of every session
+		authGroup.POST("/logout-all", RequireAuth(), controllers.LogoutAll)
+
+		// List and revoke sessions
+		authGroup.GET("/sessions", RequireAuth(), controllers.GetSessions)
+		authGroup.DELETE("/sessions/:sessionId", RequireAuth(), controllers.RevokeSession)
+	}
+}
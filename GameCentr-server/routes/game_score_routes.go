@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"netgames-go-server/auth"
 	"netgames-go-server/controllers"
 
 	"github.com/gin-gonic/gin"
@@ -12,6 +13,13 @@ func SetupGameScoreRoutes(router *gin.Engine) {
     {
         gameTypesGroup.GET("", controllers.GetAllGameTypes)
         gameTypesGroup.GET("/:gameCode", controllers.GetGameTypeByCode)
+        gameTypesGroup.GET("/:gameCode/schema", controllers.GetGameMetadataSchema)
+
+        // Manage game types (admin only)
+        gameTypesGroup.POST("", RequireAuth(), RequirePermission(auth.PermManageGameTypes), controllers.CreateGameType)
+        gameTypesGroup.PUT("/:gameCode", RequireAuth(), RequirePermission(auth.PermManageGameTypes), controllers.UpdateGameType)
+        gameTypesGroup.POST("/:gameCode/disable", RequireAuth(), RequirePermission(auth.PermManageGameTypes), controllers.DisableGameType)
+        gameTypesGroup.POST("/:gameCode/enable", RequireAuth(), RequirePermission(auth.PermManageGameTypes), controllers.EnableGameType)
     }
 
     // Game scores routes
@@ -22,11 +30,19 @@ func SetupGameScoreRoutes(router *gin.Engine) {
         
         // Game-specific leaderboard
         gameGroup.GET("/:gameCode/leaderboard", controllers.GetGameLeaderboard)
+        gameGroup.GET("/:gameCode/leaderboard/user/:userId", controllers.GetUserLeaderboardPosition)
+        gameGroup.GET("/:gameCode/leaderboard/rating", controllers.GetGameRatingLeaderboard)
         
+        // Game sessions that scores are submitted against
+        gameGroup.POST("/:gameCode/session", RequireAuth(), controllers.StartGameSession)
+
         // Game scores
         gameGroup.GET("/:gameCode/score", controllers.GetAllScores)
-        gameGroup.POST("/:gameCode/score", controllers.PostScore)
-        gameGroup.POST("/:gameCode/score/:scoreId/comment", controllers.AddCommentToScore)
+        gameGroup.POST("/:gameCode/score", RequireAuth(), controllers.PostScore)
+        gameGroup.POST("/:gameCode/score/:scoreId/comment", RequireAuth(), controllers.AddCommentToScore)
+        gameGroup.PUT("/:gameCode/score/:scoreId/comment/:commentId", RequireAuth(), controllers.EditComment)
+        gameGroup.DELETE("/:gameCode/score/:scoreId/comment/:commentId", RequireAuth(), controllers.DeleteComment)
+        gameGroup.GET("/:gameCode/score/:scoreId/comment/:commentId/history", RequireAuth(), RequirePermission(auth.PermModerateComments), controllers.GetCommentHistory)
         
         // User game scores
         gameGroup.GET("/:gameCode/user/:userId/scores", controllers.GetUserGameScores)
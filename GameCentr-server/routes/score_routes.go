@@ -17,9 +17,9 @@ func SetupScoreRoutes(router *gin.Engine) {
 		scoreGroup.GET("/:scoreId", controllers.GetScoreById)
 
 		// Post a new score
-		scoreGroup.POST("", controllers.PostScore)
+		scoreGroup.POST("", RequireAuth(), controllers.PostScore)
 
 		// Add comment to score
-		scoreGroup.POST("/addComment", controllers.AddCommentToScore)
+		scoreGroup.POST("/addComment", RequireAuth(), controllers.AddCommentToScore)
 	}
 }
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"netgames-go-server/auth"
+	"netgames-go-server/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupSeasonRoutes configures all routes related to leaderboard seasons
+func SetupSeasonRoutes(router *gin.Engine) {
+	seasonGroup := router.Group("/seasons")
+	{
+		// List and view seasons
+		seasonGroup.GET("", controllers.GetSeasons)
+		seasonGroup.GET("/:seasonId", controllers.GetSeason)
+
+		// Final (or live) standings of a game in a season
+		seasonGroup.GET("/:seasonId/standings/:gameCode", controllers.GetSeasonStandings)
+
+		// Define seasons (admin only)
+		seasonGroup.POST("", RequireAuth(), RequirePermission(auth.PermManageSeasons), controllers.CreateSeason)
+		seasonGroup.PUT("/:seasonId", RequireAuth(), RequirePermission(auth.PermManageSeasons), controllers.UpdateSeason)
+	}
+}
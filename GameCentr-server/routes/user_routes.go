@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"netgames-go-server/auth"
 	"netgames-go-server/controllers"
 
 	"github.com/gin-gonic/gin"
@@ -24,5 +25,11 @@ func SetupUserRoutes(router *gin.Engine) {
 
 		// Add user (register)
 		userGroup.POST("/addUser", controllers.AddUser)
+
+		// View a user with their achievement history (admin only)
+		userGroup.GET("/:userId/admin", RequireAuth(), RequirePermission(auth.PermManageUsers), controllers.GetUserAdminView)
+
+		// Change a user's role (admin only)
+		userGroup.PUT("/:userId/role", RequireAuth(), RequirePermission(auth.PermManageUsers), controllers.SetUserRole)
 	}
 }
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"netgames-go-server/db"
+	"netgames-go-server/models"
+)
+
+// RecordAchievementAudit appends an award or revocation to the audit trail
+func RecordAchievementAudit(ctx context.Context, entry models.AchievementAudit) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	_, err := db.AchievementAuditColl.InsertOne(ctx, entry)
+	return err
+}
+
+// GetAchievementAudit returns a user's audit trail, newest first
+func GetAchievementAudit(ctx context.Context, userID primitive.ObjectID) ([]models.AchievementAudit, error) {
+	cursor, err := db.AchievementAuditColl.Find(
+		ctx,
+		bson.M{"user_id": userID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []models.AchievementAudit{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// RevokeAchievements removes a user's unlock of an achievement, or of every
+// achievement when achievementID is nil, together with the stored progress.
+// Revoking an achievement also revokes the user's unlocks that depend on it:
+// achievements chained after it and the higher tiers of its tier group. Each
+// revoked achievement is marked so that rules do not award it again on the
+// user's next score. Every revocation is audited. It returns the audit entries
+// of the revoked achievements.
+func RevokeAchievements(ctx context.Context, userID primitive.ObjectID, achievementID *primitive.ObjectID, actorID primitive.ObjectID, reason string) ([]models.AchievementAudit, error) {
+	cursor, err := db.UserAchievementColl.Find(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	var unlocks []models.UserAchievement
+	if err := cursor.All(ctx, &unlocks); err != nil {
+		return nil, err
+	}
+
+	// Look up the codes of the unlocked achievements for the audit trail
+	ids := make([]primitive.ObjectID, 0, len(unlocks))
+	for _, unlock := range unlocks {
+		ids = append(ids, unlock.AchievementID)
+	}
+	achievementCursor, err := db.AchievementColl.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	var achievements []models.Achievement
+	if err := achievementCursor.All(ctx, &achievements); err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]models.Achievement, len(achievements))
+	for _, achievement := range achievements {
+		byID[achievement.ID] = achievement
+	}
+
+	// Work out the reason for each achievement to revoke
+	reasons := make(map[primitive.ObjectID]string, len(unlocks))
+	if achievementID == nil {
+		for _, unlock := range unlocks {
+			reasons[unlock.AchievementID] = reason
+		}
+	} else {
+		root, ok := byID[*achievementID]
+		if !ok {
+			// The user does not have the achievement
+			return []models.AchievementAudit{}, nil
+		}
+		reasons[root.ID] = reason
+
+		gameCursor, err := db.AchievementColl.Find(ctx, bson.M{"game_code": root.GameCode})
+		if err != nil {
+			return nil, err
+		}
+		var gameAchievements []models.Achievement
+		if err := gameCursor.All(ctx, &gameAchievements); err != nil {
+			return nil, err
+		}
+		for _, dependent := range DependentAchievements(root, gameAchievements) {
+			reasons[dependent.ID] = fmt.Sprintf("%s (depends on %s)", reason, root.Code)
+		}
+	}
+
+	revoked := []models.AchievementAudit{}
+	for _, unlock := range unlocks {
+		unlockReason, ok := reasons[unlock.AchievementID]
+		if !ok {
+			continue
+		}
+
+		result, err := db.UserAchievementColl.DeleteOne(ctx, bson.M{"_id": unlock.ID})
+		if err != nil {
+			return revoked, err
+		}
+		if result.DeletedCount == 0 {
+			// A concurrent revocation got there first
+			continue
+		}
+
+		_, err = db.AchievementProgressColl.DeleteOne(ctx, bson.M{"user_id": userID, "achievement_id": unlock.AchievementID})
+		if err != nil {
+			return revoked, err
+		}
+
+		now := time.Now()
+		_, err = db.RevokedAchievementColl.UpdateOne(
+			ctx,
+			bson.M{"user_id": userID, "achievement_id": unlock.AchievementID},
+			bson.M{"$set": models.RevokedAchievement{
+				UserID:        userID,
+				AchievementID: unlock.AchievementID,
+				RevokedBy:     actorID,
+				Reason:        unlockReason,
+				RevokedAt:     now,
+			}},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return revoked, err
+		}
+
+		achievement := byID[unlock.AchievementID]
+		entry := models.AchievementAudit{
+			UserID:        userID,
+			AchievementID: unlock.AchievementID,
+			GameCode:      achievement.GameCode,
+			Code:          achievement.Code,
+			Action:        models.AuditRevoked,
+			Source:        models.AuditSourceManual,
+			ActorID:       &actorID,
+			Reason:        unlockReason,
+			CreatedAt:     now,
+		}
+		if err := RecordAchievementAudit(ctx, entry); err != nil {
+			return revoked, err
+		}
+		revoked = append(revoked, entry)
+	}
+	return revoked, nil
+}
+
+// DependentAchievements returns the achievements of root's game that can only
+// be held together with root: those that require it, directly or through
+// other prerequisites, and the higher tiers of its tier group, together with
+// everything that depends on those in turn
+func DependentAchievements(root models.Achievement, achievements []models.Achievement) []models.Achievement {
+	dependents := []models.Achievement{}
+	seen := map[primitive.ObjectID]bool{root.ID: true}
+	for queue := []models.Achievement{root}; len(queue) > 0; queue = queue[1:] {
+		current := queue[0]
+		for _, achievement := range achievements {
+			if seen[achievement.ID] || achievement.GameCode != current.GameCode {
+				continue
+			}
+			higherTier := current.TierGroup != "" && achievement.TierGroup == current.TierGroup && achievement.Tier > current.Tier
+			if higherTier || slices.Contains(achievement.Prerequisites, current.Code) {
+				seen[achievement.ID] = true
+				dependents = append(dependents, achievement)
+				queue = append(queue, achievement)
+			}
+		}
+	}
+	return dependents
+}
+
+// ClearRevocation lets rules award an achievement to a user again. It is
+// called when an admin awards a revoked achievement manually.
+func ClearRevocation(ctx context.Context, userID, achievementID primitive.ObjectID) error {
+	_, err := db.RevokedAchievementColl.DeleteOne(ctx, bson.M{"user_id": userID, "achievement_id": achievementID})
+	return err
+}
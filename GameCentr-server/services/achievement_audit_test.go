@@ -0,0 +1,57 @@
+package services
+
+import (
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"netgames-go-server/models"
+)
+
+func TestDependentAchievements(t *testing.T) {
+	achievement := func(code, tierGroup string, tier int, prerequisites ...string) models.Achievement {
+		return models.Achievement{
+			ID:            primitive.NewObjectID(),
+			GameCode:      "whackamole",
+			Code:          code,
+			TierGroup:     tierGroup,
+			Tier:          tier,
+			Prerequisites: prerequisites,
+		}
+	}
+	novice := achievement("mole_novice", "", 0)
+	bronze := achievement("mole_hunter", "mole_hunter", models.TierBronze, "mole_novice")
+	silver := achievement("mole_hunter_silver", "mole_hunter", models.TierSilver)
+	gold := achievement("mole_hunter_gold", "mole_hunter", models.TierGold)
+	master := achievement("mole_master", "", 0, "mole_hunter_gold")
+	frenzy := achievement("mole_frenzy", "", 0)
+	other := models.Achievement{ID: primitive.NewObjectID(), GameCode: "pong", Code: "rally", Prerequisites: []string{"mole_novice"}}
+	achievements := []models.Achievement{novice, bronze, silver, gold, master, frenzy, other}
+
+	tests := []struct {
+		name string
+		root models.Achievement
+		want []string
+	}{
+		{"chain through every tier", novice, []string{"mole_hunter", "mole_hunter_silver", "mole_hunter_gold", "mole_master"}},
+		{"higher tiers only", silver, []string{"mole_hunter_gold", "mole_master"}},
+		{"highest tier", gold, []string{"mole_master"}},
+		{"nothing depends on it", frenzy, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, dependent := range DependentAchievements(tt.root, achievements) {
+				got = append(got, dependent.Code)
+			}
+			slices.Sort(got)
+			want := slices.Clone(tt.want)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("DependentAchievements(%s) = %v, want %v", tt.root.Code, got, want)
+			}
+		})
+	}
+}
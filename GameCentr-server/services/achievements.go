@@ -0,0 +1,735 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"netgames-go-server/db"
+	"netgames-go-server/models"
+)
+
+var ErrInvalidCriteria = errors.New("invalid criteria, each rule needs exactly one of all, any, not or a field with a known op and a value")
+
+// criteriaOps are the operators a rule can compare a fact with
+var criteriaOps = map[string]bool{
+	models.OpEq:      true,
+	models.OpNe:      true,
+	models.OpGt:      true,
+	models.OpGte:     true,
+	models.OpLt:      true,
+	models.OpLte:     true,
+	models.OpSizeGte: true,
+}
+
+// statTotals are the stats.* facts that sum a numeric metadata field over all
+// of a player's scores in the game
+var statTotals = map[string]string{
+	"totalWords":        "wordsTyped",
+	"totalMolesWhacked": "molesWhacked",
+}
+
+// cumulativeStats are the stats.* facts that never decrease, so progress
+// towards them can be persisted
+var cumulativeStats = map[string]bool{
+	"playCount":         true,
+	"winCount":          true,
+	"achievementCount":  true,
+	"totalWords":        true,
+	"totalMolesWhacked": true,
+}
+
+// CounterTarget returns the counter and target of a counter achievement, whose
+// criteria is a single "at least" comparison on a cumulative stat
+func CounterTarget(criteria *models.Criteria) (string, float64, bool) {
+	if criteria == nil || criteria.Op != models.OpGte || !strings.HasPrefix(criteria.Field, "stats.") {
+		return "", 0, false
+	}
+	if !cumulativeStats[strings.TrimPrefix(criteria.Field, "stats.")] {
+		return "", 0, false
+	}
+	target, ok := toNumber(criteria.Value)
+	if !ok || target <= 0 {
+		return "", 0, false
+	}
+	return criteria.Field, target, true
+}
+
+// AchievementFacts combines the per-run facts reported for a game event with
+// the facts only the server can vouch for: the player's stats under "stats"
+// and the server time under "server". Reported facts cannot override them.
+func AchievementFacts(reported map[string]interface{}, stats map[string]interface{}, now time.Time) map[string]interface{} {
+	facts := make(map[string]interface{}, len(reported)+2)
+	for key, value := range reported {
+		facts[key] = value
+	}
+	facts["stats"] = stats
+	facts["server"] = map[string]interface{}{
+		"hour": float64(now.Hour()),
+	}
+	return facts
+}
+
+// UserStatFacts derives a player's counters from the scores and
+// user_achievements collections:
+//   - playCount: scores posted in the game
+//   - winCount: won runs of a binary game
+//   - gamesPlayedToday: distinct games played since midnight UTC
+//   - achievementCount: achievements earned in any game
+//   - the totals in statTotals
+func UserStatFacts(ctx context.Context, userID primitive.ObjectID, gameCode string, now time.Time) (map[string]interface{}, error) {
+	var gameType models.GameType
+	err := db.GameTypeColl.FindOne(ctx, bson.M{"game_code": gameCode}).Decode(&gameType)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	// Runs of binary games are won when they score above zero
+	isWin := bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$value", 0}}, 1, 0}}
+	if gameType.ScoringType != models.ScoringBinary {
+		isWin = bson.M{"$literal": 0}
+	}
+
+	group := bson.M{
+		"_id":       nil,
+		"playCount": bson.M{"$sum": 1},
+		"winCount":  bson.M{"$sum": isWin},
+	}
+	for stat, field := range statTotals {
+		group[stat] = bson.M{"$sum": "$metadata." + field}
+	}
+
+	cursor, err := db.ScoreColl.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"owner": userID, "game": gameCode}}},
+		{{Key: "$group", Value: group}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []bson.M
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	stats := map[string]interface{}{
+		"playCount": float64(0),
+		"winCount":  float64(0),
+	}
+	for stat := range statTotals {
+		stats[stat] = float64(0)
+	}
+	if len(results) > 0 {
+		for stat, value := range results[0] {
+			if number, ok := toNumber(value); ok {
+				stats[stat] = number
+			}
+		}
+	}
+
+	today, err := ParseTimeFrame(TimeFrameDaily, "", now)
+	if err != nil {
+		return nil, err
+	}
+	gamesToday, err := db.ScoreColl.Distinct(ctx, "game", bson.M{"owner": userID, "createdAt": bson.M{"$gte": today.Start}})
+	if err != nil {
+		return nil, err
+	}
+	stats["gamesPlayedToday"] = float64(len(gamesToday))
+
+	achievementCount, err := db.UserAchievementColl.CountDocuments(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	stats["achievementCount"] = float64(achievementCount)
+
+	return stats, nil
+}
+
+// EvaluateAchievements awards every achievement of the game (and every global
+// achievement) that is not retired or revoked from the player and whose
+// criteria the player now meets, given the per-run facts of a game event. It
+// returns the newly awarded achievements.
+func EvaluateAchievements(ctx context.Context, userID primitive.ObjectID, gameCode string, reported map[string]interface{}) ([]models.AchievementWithDetails, error) {
+	cursor, err := db.AchievementColl.Find(ctx, bson.M{
+		"$or": []bson.M{
+			{"game_code": gameCode},
+			{"game_code": "all"},
+		},
+		"retired": bson.M{"$ne": true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var achievements []models.Achievement
+	if err := cursor.All(ctx, &achievements); err != nil {
+		return nil, err
+	}
+
+	// Skip the achievements the player already has
+	earned, err := db.UserAchievementColl.Distinct(ctx, "achievement_id", bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[primitive.ObjectID]bool, len(earned))
+	for _, id := range earned {
+		if objectID, ok := id.(primitive.ObjectID); ok {
+			existing[objectID] = true
+		}
+	}
+
+	// Nor award the achievements an admin revoked from them
+	revokedIDs, err := db.RevokedAchievementColl.Distinct(ctx, "achievement_id", bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	revoked := make(map[primitive.ObjectID]bool, len(revokedIDs))
+	for _, id := range revokedIDs {
+		if objectID, ok := id.(primitive.ObjectID); ok {
+			revoked[objectID] = true
+		}
+	}
+
+	// Counters come from the server, only per-run facts from the reported progress
+	now := time.Now()
+	stats, err := UserStatFacts(ctx, userID, gameCode, now)
+	if err != nil {
+		return nil, err
+	}
+	facts := AchievementFacts(reported, stats, now)
+
+	gameNames, err := gameNamesByCode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Chained achievements are only evaluated once their prerequisites are
+	// unlocked, possibly earlier in this same evaluation
+	unlockedCodes := make(map[string]bool, len(achievements))
+	pending := []models.Achievement{}
+	for _, achievement := range achievements {
+		switch {
+		case existing[achievement.ID]:
+			unlockedCodes[AchievementKey(achievement.GameCode, achievement.Code)] = true
+		case !revoked[achievement.ID]:
+			pending = append(pending, achievement)
+		}
+	}
+
+	awarded := []models.AchievementWithDetails{}
+	for evaluated := true; evaluated; {
+		evaluated = false
+		var blocked []models.Achievement
+		for _, achievement := range pending {
+			if !PrerequisitesMet(achievement, unlockedCodes) {
+				blocked = append(blocked, achievement)
+				continue
+			}
+			evaluated = true
+
+			if !EvaluateCriteria(achievement.Criteria, facts) {
+				// Remember how far the player got towards counter achievements
+				if err := recordProgress(ctx, userID, achievement, facts, now); err != nil {
+					log.Printf("Error recording progress of achievement %s: %v", achievement.Code, err)
+				}
+				continue
+			}
+
+			userAchievement := models.UserAchievement{
+				UserID:        userID,
+				AchievementID: achievement.ID,
+				GameCode:      gameCode,
+				AwardedAt:     time.Now(),
+			}
+			if _, err := db.UserAchievementColl.InsertOne(ctx, userAchievement); err != nil {
+				// A concurrent evaluation may have awarded it first
+				log.Printf("Error awarding achievement %s: %v", achievement.Code, err)
+				continue
+			}
+			stats["achievementCount"] = stats["achievementCount"].(float64) + 1
+			unlockedCodes[AchievementKey(achievement.GameCode, achievement.Code)] = true
+
+			err := RecordAchievementAudit(ctx, models.AchievementAudit{
+				UserID:        userID,
+				AchievementID: achievement.ID,
+				GameCode:      achievement.GameCode,
+				Code:          achievement.Code,
+				Action:        models.AuditAwarded,
+				Source:        models.AuditSourceRule,
+				CreatedAt:     userAchievement.AwardedAt,
+			})
+			if err != nil {
+				log.Printf("Error auditing achievement %s: %v", achievement.Code, err)
+			}
+
+			if err := recordProgress(ctx, userID, achievement, facts, now); err != nil {
+				log.Printf("Error recording progress of achievement %s: %v", achievement.Code, err)
+			}
+
+			awarded = append(awarded, AchievementDetails(achievement, gameNames, &userAchievement.AwardedAt))
+		}
+		pending = blocked
+	}
+	return awarded, nil
+}
+
+// AchievementKey identifies an achievement by its game and code
+func AchievementKey(gameCode, code string) string {
+	return gameCode + "/" + code
+}
+
+// PrerequisitesMet reports whether every prerequisite of an achievement is
+// unlocked, given the keys of the unlocked achievements
+func PrerequisitesMet(achievement models.Achievement, unlockedCodes map[string]bool) bool {
+	for _, code := range achievement.Prerequisites {
+		if !unlockedCodes[AchievementKey(achievement.GameCode, code)] {
+			return false
+		}
+	}
+	return true
+}
+
+// recordProgress stores the player's counter value for a counter achievement.
+// Progress is capped at the target and never goes backwards.
+func recordProgress(ctx context.Context, userID primitive.ObjectID, achievement models.Achievement, facts map[string]interface{}, now time.Time) error {
+	field, target, ok := CounterTarget(achievement.Criteria)
+	if !ok {
+		return nil
+	}
+	value, ok := lookupFact(facts, field)
+	if !ok {
+		return nil
+	}
+	progress, ok := toNumber(value)
+	if !ok {
+		return nil
+	}
+
+	_, err := db.AchievementProgressColl.UpdateOne(
+		ctx,
+		bson.M{"user_id": userID, "achievement_id": achievement.ID},
+		bson.M{
+			"$max": bson.M{"progress": math.Min(progress, target)},
+			"$set": bson.M{"target": target, "updated_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// GetAchievementProgress returns a user's stored progress keyed by achievement ID
+func GetAchievementProgress(ctx context.Context, userID primitive.ObjectID) (map[primitive.ObjectID]models.AchievementProgress, error) {
+	cursor, err := db.AchievementProgressColl.Find(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []models.AchievementProgress
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	progress := make(map[primitive.ObjectID]models.AchievementProgress, len(records))
+	for _, record := range records {
+		progress[record.AchievementID] = record
+	}
+	return progress, nil
+}
+
+// WithProgress fills in the progress fields of a counter achievement from the
+// stored progress. Unlocked achievements are always complete.
+func WithProgress(details models.AchievementWithDetails, criteria *models.Criteria, record *models.AchievementProgress) models.AchievementWithDetails {
+	_, target, ok := CounterTarget(criteria)
+	if !ok {
+		return details
+	}
+
+	var progress float64
+	switch {
+	case details.IsUnlocked:
+		progress = target
+	case record != nil:
+		progress = math.Min(record.Progress, target)
+	}
+	percent := math.Round(progress/target*1000) / 10
+
+	details.Progress = &progress
+	details.Target = &target
+	details.Percent = &percent
+	return details
+}
+
+// AchievementDetails describes an achievement for display. awardedAt is nil
+// for achievements the user has not unlocked.
+func AchievementDetails(achievement models.Achievement, gameNames map[string]string, awardedAt *time.Time) models.AchievementWithDetails {
+	gameName, ok := gameNames[achievement.GameCode]
+	if !ok {
+		gameName = achievement.GameCode
+	}
+
+	return models.AchievementWithDetails{
+		ID:          achievement.ID,
+		GameCode:    achievement.GameCode,
+		GameName:    gameName,
+		Code:        achievement.Code,
+		Title:       achievement.Title,
+		Description: achievement.Description,
+		Icon:        achievement.Icon,
+		Difficulty:  achievement.Difficulty,
+		IsHidden:    achievement.IsHidden,
+		IsUnlocked:  awardedAt != nil,
+		AwardedAt:   awardedAt,
+		Points:      achievement.PointValue(),
+		TierGroup:   achievement.TierGroup,
+		Tier:        achievement.Tier,
+		TierName:    models.TierNames[achievement.Tier],
+	}
+}
+
+// GroupTiers collapses the tiers of each tier group into a single item, placed
+// where the group's first tier was. The item describes the next tier to unlock
+// (or the highest tier once all are unlocked), while Tier, IsUnlocked and
+// AwardedAt describe the highest tier unlocked so far.
+func GroupTiers(details []models.AchievementWithDetails) []models.AchievementWithDetails {
+	groups := make(map[string][]models.AchievementWithDetails)
+	for _, item := range details {
+		if item.TierGroup != "" {
+			groups[item.TierGroup] = append(groups[item.TierGroup], item)
+		}
+	}
+
+	grouped := make([]models.AchievementWithDetails, 0, len(details))
+	for _, item := range details {
+		if item.TierGroup == "" {
+			grouped = append(grouped, item)
+			continue
+		}
+		tiers, ok := groups[item.TierGroup]
+		if !ok {
+			// The group was already added at its first tier
+			continue
+		}
+		delete(groups, item.TierGroup)
+		grouped = append(grouped, groupTier(tiers))
+	}
+	return grouped
+}
+
+// groupTier builds the single item of a tier group from its tiers
+func groupTier(tiers []models.AchievementWithDetails) models.AchievementWithDetails {
+	sort.SliceStable(tiers, func(i, j int) bool { return tiers[i].Tier < tiers[j].Tier })
+
+	var current *models.AchievementWithDetails
+	item := tiers[len(tiers)-1]
+	for i := range tiers {
+		if tiers[i].IsUnlocked {
+			current = &tiers[i]
+		}
+	}
+	for _, tier := range tiers {
+		if !tier.IsUnlocked {
+			item = tier
+			break
+		}
+	}
+
+	item.Tier, item.TierName, item.IsUnlocked, item.AwardedAt = 0, "", false, nil
+	if current != nil {
+		item.Tier = current.Tier
+		item.TierName = current.TierName
+		item.IsUnlocked = true
+		item.AwardedAt = current.AwardedAt
+	}
+
+	item.Tiers = make([]models.AchievementTier, 0, len(tiers))
+	for _, tier := range tiers {
+		item.Tiers = append(item.Tiers, models.AchievementTier{
+			ID:          tier.ID,
+			Code:        tier.Code,
+			Title:       tier.Title,
+			Description: tier.Description,
+			Tier:        tier.Tier,
+			TierName:    tier.TierName,
+			Points:      tier.Points,
+			IsUnlocked:  tier.IsUnlocked,
+			AwardedAt:   tier.AwardedAt,
+		})
+	}
+	return item
+}
+
+// AchievementUnlockPercents returns, per achievement, the share of active
+// users (users with at least one score) who unlocked it
+func AchievementUnlockPercents(ctx context.Context) (map[primitive.ObjectID]float64, error) {
+	activeUsers, err := countActiveUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := db.UserAchievementColl.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$achievement_id",
+			"count": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var counts []struct {
+		AchievementID primitive.ObjectID `bson:"_id"`
+		Count         int64              `bson:"count"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+
+	percents := make(map[primitive.ObjectID]float64, len(counts))
+	if activeUsers == 0 {
+		return percents, nil
+	}
+	for _, count := range counts {
+		// Manually awarded achievements can belong to users without scores
+		percent := math.Min(100, float64(count.Count)/float64(activeUsers)*100)
+		percents[count.AchievementID] = math.Round(percent*10) / 10
+	}
+	return percents, nil
+}
+
+// countActiveUsers counts the users who posted at least one score
+func countActiveUsers(ctx context.Context) (int64, error) {
+	cursor, err := db.ScoreColl.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{"_id": "$owner"}}},
+		{{Key: "$count", Value: "users"}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []struct {
+		Users int64 `bson:"users"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return 0, err
+	}
+	if len(result) == 0 {
+		return 0, nil
+	}
+	return result[0].Users, nil
+}
+
+// AchievementScore returns the total points of the achievements a user unlocked
+func AchievementScore(ctx context.Context, userID primitive.ObjectID) (int, error) {
+	earned, err := db.UserAchievementColl.Distinct(ctx, "achievement_id", bson.M{"user_id": userID})
+	if err != nil {
+		return 0, err
+	}
+	if len(earned) == 0 {
+		return 0, nil
+	}
+
+	cursor, err := db.AchievementColl.Find(ctx, bson.M{"_id": bson.M{"$in": earned}})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var achievements []models.Achievement
+	if err := cursor.All(ctx, &achievements); err != nil {
+		return 0, err
+	}
+
+	score := 0
+	for _, achievement := range achievements {
+		score += achievement.PointValue()
+	}
+	return score, nil
+}
+
+// gameNamesByCode maps game codes to display names, with "all" for global achievements
+func gameNamesByCode(ctx context.Context) (map[string]string, error) {
+	gameTypes, err := allGameTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gameNames := make(map[string]string, len(gameTypes)+1)
+	for code, gameType := range gameTypes {
+		gameNames[code] = gameType.Name
+	}
+	gameNames["all"] = "Global"
+	return gameNames, nil
+}
+
+// EvaluateCriteria reports whether the facts satisfy an achievement rule.
+// Achievements without criteria are never unlocked automatically.
+func EvaluateCriteria(criteria *models.Criteria, facts map[string]interface{}) bool {
+	if criteria == nil {
+		return false
+	}
+
+	switch {
+	case len(criteria.All) > 0:
+		for i := range criteria.All {
+			if !EvaluateCriteria(&criteria.All[i], facts) {
+				return false
+			}
+		}
+		return true
+	case len(criteria.Any) > 0:
+		for i := range criteria.Any {
+			if EvaluateCriteria(&criteria.Any[i], facts) {
+				return true
+			}
+		}
+		return false
+	case criteria.Not != nil:
+		return !EvaluateCriteria(criteria.Not, facts)
+	}
+
+	fact, ok := lookupFact(facts, criteria.Field)
+	if !ok {
+		return false
+	}
+	return compareFact(criteria.Op, fact, criteria.Value)
+}
+
+// ValidateCriteria checks that every node of a rule either combines other
+// rules or compares a field using a known operator
+func ValidateCriteria(criteria *models.Criteria) error {
+	if criteria == nil {
+		return nil
+	}
+
+	kinds := 0
+	for _, set := range []bool{len(criteria.All) > 0, len(criteria.Any) > 0, criteria.Not != nil, criteria.Field != ""} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return ErrInvalidCriteria
+	}
+
+	switch {
+	case len(criteria.All) > 0:
+		for i := range criteria.All {
+			if err := ValidateCriteria(&criteria.All[i]); err != nil {
+				return err
+			}
+		}
+	case len(criteria.Any) > 0:
+		for i := range criteria.Any {
+			if err := ValidateCriteria(&criteria.Any[i]); err != nil {
+				return err
+			}
+		}
+	case criteria.Not != nil:
+		return ValidateCriteria(criteria.Not)
+	default:
+		if !criteriaOps[criteria.Op] || criteria.Value == nil {
+			return ErrInvalidCriteria
+		}
+	}
+	return nil
+}
+
+// lookupFact resolves a dotted path such as "server.hour" in the facts
+func lookupFact(facts map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = facts
+	for _, key := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = object[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// compareFact applies an operator to a fact. Facts of a different type than
+// the value never match.
+func compareFact(op string, fact, value interface{}) bool {
+	if op == models.OpSizeGte {
+		threshold, ok := toNumber(value)
+		if !ok {
+			return false
+		}
+		switch collection := fact.(type) {
+		case map[string]interface{}:
+			return float64(len(collection)) >= threshold
+		case []interface{}:
+			return float64(len(collection)) >= threshold
+		}
+		return false
+	}
+
+	if factNumber, ok := toNumber(fact); ok {
+		valueNumber, ok := toNumber(value)
+		if !ok {
+			return false
+		}
+		switch op {
+		case models.OpEq:
+			return factNumber == valueNumber
+		case models.OpNe:
+			return factNumber != valueNumber
+		case models.OpGt:
+			return factNumber > valueNumber
+		case models.OpGte:
+			return factNumber >= valueNumber
+		case models.OpLt:
+			return factNumber < valueNumber
+		case models.OpLte:
+			return factNumber <= valueNumber
+		}
+		return false
+	}
+
+	// Booleans and strings can only be compared for equality
+	switch fact.(type) {
+	case bool, string:
+		switch op {
+		case models.OpEq:
+			return fact == value
+		case models.OpNe:
+			return fact != value
+		}
+	}
+	return false
+}
+
+// toNumber converts any numeric value decoded from JSON or BSON to float64
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"netgames-go-server/models"
+)
+
+// testFacts are the facts of a typing run by a player with some history
+var testFacts = AchievementFacts(
+	map[string]interface{}{
+		"wpm":       float64(72),
+		"completed": true,
+		"mode":      "sentences",
+		"letters":   map[string]interface{}{"a": true, "b": true, "c": true},
+		"guesses":   []interface{}{10.0, 20.0},
+		"stats":     map[string]interface{}{"playCount": float64(1000)},
+	},
+	map[string]interface{}{"playCount": float64(12), "totalWords": int64(480)},
+	time.Date(2026, 3, 14, 2, 30, 0, 0, time.UTC),
+)
+
+func compare(field, op string, value interface{}) models.Criteria {
+	return models.Criteria{Field: field, Op: op, Value: value}
+}
+
+func TestEvaluateCriteria(t *testing.T) {
+	tests := []struct {
+		name     string
+		criteria *models.Criteria
+		want     bool
+	}{
+		{"no criteria", nil, false},
+		{"number gte", &models.Criteria{Field: "wpm", Op: models.OpGte, Value: 72}, true},
+		{"number gt", &models.Criteria{Field: "wpm", Op: models.OpGt, Value: 72}, false},
+		{"missing fact", &models.Criteria{Field: "accuracy", Op: models.OpGte, Value: 0}, false},
+		{"server fact", &models.Criteria{Field: "server.hour", Op: models.OpLt, Value: 4}, true},
+		{"server stats win over reported ones", &models.Criteria{Field: "stats.playCount", Op: models.OpEq, Value: 12}, true},
+		{"int64 stat", &models.Criteria{Field: "stats.totalWords", Op: models.OpGte, Value: 480.0}, true},
+		{"all met", &models.Criteria{All: []models.Criteria{
+			compare("wpm", models.OpGte, 60),
+			compare("completed", models.OpEq, true),
+		}}, true},
+		{"all not met", &models.Criteria{All: []models.Criteria{
+			compare("wpm", models.OpGte, 60),
+			compare("completed", models.OpEq, false),
+		}}, false},
+		{"any met", &models.Criteria{Any: []models.Criteria{
+			compare("wpm", models.OpGte, 100),
+			compare("mode", models.OpEq, "sentences"),
+		}}, true},
+		{"any not met", &models.Criteria{Any: []models.Criteria{
+			compare("wpm", models.OpGte, 100),
+			compare("mode", models.OpEq, "words"),
+		}}, false},
+		{"not", &models.Criteria{Not: &models.Criteria{Field: "completed", Op: models.OpEq, Value: true}}, false},
+		{"nested", &models.Criteria{All: []models.Criteria{
+			{Not: &models.Criteria{Field: "mode", Op: models.OpEq, Value: "words"}},
+			{Any: []models.Criteria{compare("letters", models.OpSizeGte, 3)}},
+		}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EvaluateCriteria(tt.criteria, testFacts); got != tt.want {
+				t.Errorf("EvaluateCriteria() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCriteria(t *testing.T) {
+	tests := []struct {
+		name     string
+		criteria *models.Criteria
+		valid    bool
+	}{
+		{"no criteria", nil, true},
+		{"comparison", &models.Criteria{Field: "wpm", Op: models.OpGte, Value: 60}, true},
+		{"combined", &models.Criteria{All: []models.Criteria{
+			compare("wpm", models.OpGte, 60),
+			{Not: &models.Criteria{Field: "completed", Op: models.OpEq, Value: false}},
+		}}, true},
+		{"empty", &models.Criteria{}, false},
+		{"unknown op", &models.Criteria{Field: "wpm", Op: "between", Value: 60}, false},
+		{"missing value", &models.Criteria{Field: "wpm", Op: models.OpGte}, false},
+		{"field and combinator", &models.Criteria{Field: "wpm", Op: models.OpGte, Value: 60, All: []models.Criteria{compare("wpm", models.OpLt, 90)}}, false},
+		{"invalid nested rule", &models.Criteria{Any: []models.Criteria{
+			compare("wpm", models.OpGte, 60),
+			compare("wpm", "approx", 60),
+		}}, false},
+		{"invalid negated rule", &models.Criteria{Not: &models.Criteria{Field: "wpm"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCriteria(tt.criteria)
+			if tt.valid && err != nil {
+				t.Errorf("ValidateCriteria() = %v, want nil", err)
+			}
+			if !tt.valid && err != ErrInvalidCriteria {
+				t.Errorf("ValidateCriteria() = %v, want %v", err, ErrInvalidCriteria)
+			}
+		})
+	}
+}
+
+func TestLookupFact(t *testing.T) {
+	tests := []struct {
+		path  string
+		want  interface{}
+		found bool
+	}{
+		{"wpm", float64(72), true},
+		{"server.hour", float64(2), true},
+		{"stats.totalWords", int64(480), true},
+		{"letters.a", true, true},
+		{"accuracy", nil, false},
+		{"stats.missing", nil, false},
+		{"wpm.value", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, found := lookupFact(testFacts, tt.path)
+			if found != tt.found || got != tt.want {
+				t.Errorf("lookupFact(%q) = %v, %v, want %v, %v", tt.path, got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
+
+func TestCompareFact(t *testing.T) {
+	tests := []struct {
+		name  string
+		op    string
+		fact  interface{}
+		value interface{}
+		want  bool
+	}{
+		{"eq across number types", models.OpEq, int32(5), 5.0, true},
+		{"ne", models.OpNe, 5.0, 6, true},
+		{"gt", models.OpGt, 6.0, 5, true},
+		{"gte equal", models.OpGte, 5.0, 5, true},
+		{"lt", models.OpLt, 5.0, 5, false},
+		{"lte", models.OpLte, 5.0, 5, true},
+		{"number against string", models.OpEq, 5.0, "5", false},
+		{"unknown op on numbers", "approx", 5.0, 5, false},
+		{"bool eq", models.OpEq, true, true, true},
+		{"bool ne", models.OpNe, true, false, true},
+		{"bool ordered", models.OpGt, true, false, false},
+		{"string eq", models.OpEq, "hard", "hard", true},
+		{"string against number", models.OpEq, "5", 5.0, false},
+		{"size of map", models.OpSizeGte, map[string]interface{}{"a": 1, "b": 2}, 2, true},
+		{"size of slice", models.OpSizeGte, []interface{}{1}, 2, false},
+		{"size of number", models.OpSizeGte, 5.0, 1, false},
+		{"size with non-numeric threshold", models.OpSizeGte, []interface{}{1}, "1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareFact(tt.op, tt.fact, tt.value); got != tt.want {
+				t.Errorf("compareFact(%q, %v, %v) = %v, want %v", tt.op, tt.fact, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCounterTarget(t *testing.T) {
+	tests := []struct {
+		name      string
+		criteria  *models.Criteria
+		wantField string
+		wantValue float64
+		wantOK    bool
+	}{
+		{"cumulative stat", &models.Criteria{Field: "stats.totalWords", Op: models.OpGte, Value: 1000}, "stats.totalWords", 1000, true},
+		{"no criteria", nil, "", 0, false},
+		{"not at least", &models.Criteria{Field: "stats.playCount", Op: models.OpGt, Value: 10}, "", 0, false},
+		{"per-run fact", &models.Criteria{Field: "wpm", Op: models.OpGte, Value: 60}, "", 0, false},
+		{"stat that can decrease", &models.Criteria{Field: "stats.gamesPlayedToday", Op: models.OpGte, Value: 10}, "", 0, false},
+		{"non-numeric target", &models.Criteria{Field: "stats.playCount", Op: models.OpGte, Value: "ten"}, "", 0, false},
+		{"zero target", &models.Criteria{Field: "stats.playCount", Op: models.OpGte, Value: 0}, "", 0, false},
+		{"combined rule", &models.Criteria{All: []models.Criteria{compare("stats.playCount", models.OpGte, 10)}}, "", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field, target, ok := CounterTarget(tt.criteria)
+			if field != tt.wantField || target != tt.wantValue || ok != tt.wantOK {
+				t.Errorf("CounterTarget() = %q, %v, %v, want %q, %v, %v", field, target, ok, tt.wantField, tt.wantValue, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestWithProgress(t *testing.T) {
+	counter := &models.Criteria{Field: "stats.playCount", Op: models.OpGte, Value: 50}
+
+	tests := []struct {
+		name        string
+		criteria    *models.Criteria
+		unlocked    bool
+		record      *models.AchievementProgress
+		wantPercent *float64
+		wantValue   float64
+	}{
+		{"not a counter", &models.Criteria{Field: "wpm", Op: models.OpGte, Value: 60}, false, nil, nil, 0},
+		{"no progress yet", counter, false, nil, floatPtr(0), 0},
+		{"partway", counter, false, &models.AchievementProgress{Progress: 20}, floatPtr(40), 20},
+		{"rounded percent", counter, false, &models.AchievementProgress{Progress: 1}, floatPtr(2), 1},
+		{"capped at the target", counter, false, &models.AchievementProgress{Progress: 80}, floatPtr(100), 50},
+		{"unlocked is complete", counter, true, nil, floatPtr(100), 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WithProgress(models.AchievementWithDetails{IsUnlocked: tt.unlocked}, tt.criteria, tt.record)
+			if tt.wantPercent == nil {
+				if got.Progress != nil || got.Target != nil || got.Percent != nil {
+					t.Errorf("WithProgress() set progress %v/%v on a non-counter achievement", got.Progress, got.Target)
+				}
+				return
+			}
+			if got.Progress == nil || got.Target == nil || got.Percent == nil {
+				t.Fatalf("WithProgress() left progress unset")
+			}
+			if *got.Progress != tt.wantValue || *got.Target != 50 || *got.Percent != *tt.wantPercent {
+				t.Errorf("WithProgress() = %v/%v (%v%%), want %v/50 (%v%%)", *got.Progress, *got.Target, *got.Percent, tt.wantValue, *tt.wantPercent)
+			}
+		})
+	}
+}
+
+func TestGroupTiers(t *testing.T) {
+	awardedAt := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	tier := func(code string, level int, unlocked bool) models.AchievementWithDetails {
+		item := models.AchievementWithDetails{
+			Code:      code,
+			TierGroup: "mole_hunter",
+			Tier:      level,
+			TierName:  models.TierNames[level],
+		}
+		if unlocked {
+			item.IsUnlocked = true
+			item.AwardedAt = &awardedAt
+		}
+		return item
+	}
+	single := models.AchievementWithDetails{Code: "mole_novice"}
+
+	tests := []struct {
+		name         string
+		details      []models.AchievementWithDetails
+		wantCodes    []string
+		wantTier     int
+		wantUnlocked bool
+	}{
+		{"none unlocked", []models.AchievementWithDetails{
+			tier("mole_hunter", models.TierBronze, false),
+			single,
+			tier("mole_hunter_silver", models.TierSilver, false),
+		}, []string{"mole_hunter", "mole_novice"}, 0, false},
+		{"bronze unlocked shows silver", []models.AchievementWithDetails{
+			single,
+			tier("mole_hunter_silver", models.TierSilver, false),
+			tier("mole_hunter", models.TierBronze, true),
+			tier("mole_hunter_gold", models.TierGold, false),
+		}, []string{"mole_novice", "mole_hunter_silver"}, models.TierBronze, true},
+		{"all unlocked shows gold", []models.AchievementWithDetails{
+			tier("mole_hunter", models.TierBronze, true),
+			tier("mole_hunter_silver", models.TierSilver, true),
+			tier("mole_hunter_gold", models.TierGold, true),
+		}, []string{"mole_hunter_gold"}, models.TierGold, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grouped := GroupTiers(tt.details)
+			if len(grouped) != len(tt.wantCodes) {
+				t.Fatalf("GroupTiers() returned %d items, want %d", len(grouped), len(tt.wantCodes))
+			}
+
+			var group models.AchievementWithDetails
+			for i, item := range grouped {
+				if item.Code != tt.wantCodes[i] {
+					t.Errorf("item %d = %q, want %q", i, item.Code, tt.wantCodes[i])
+				}
+				if item.TierGroup != "" {
+					group = item
+				}
+			}
+
+			if group.Tier != tt.wantTier || group.IsUnlocked != tt.wantUnlocked {
+				t.Errorf("group tier = %d (unlocked %v), want %d (unlocked %v)", group.Tier, group.IsUnlocked, tt.wantTier, tt.wantUnlocked)
+			}
+			if group.TierName != models.TierNames[tt.wantTier] {
+				t.Errorf("group tier name = %q, want %q", group.TierName, models.TierNames[tt.wantTier])
+			}
+			if tt.wantUnlocked != (group.AwardedAt != nil) {
+				t.Errorf("group awarded at = %v, want set %v", group.AwardedAt, tt.wantUnlocked)
+			}
+			for i := 1; i < len(group.Tiers); i++ {
+				if group.Tiers[i-1].Tier >= group.Tiers[i].Tier {
+					t.Errorf("tiers are not ordered lowest first: %+v", group.Tiers)
+				}
+			}
+		})
+	}
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"netgames-go-server/db"
+	"netgames-go-server/models"
+)
+
+// Methods for combining per-game results into the global leaderboard
+const (
+	GlobalMethodPercentile = "percentile"
+	GlobalMethodZScore     = "zscore"
+	GlobalMethodRank       = "rank"
+	GlobalMethodRaw        = "raw"
+)
+
+// GlobalMethodDescriptions documents each global ranking method for API clients
+var GlobalMethodDescriptions = map[string]string{
+	GlobalMethodPercentile: "Each game contributes the percentile (0-100) of the player's best score among all players of that game. The total is the sum over the games played.",
+	GlobalMethodZScore:     "Each game contributes the number of standard deviations the player's best score lies above the mean best score of that game. The total is the sum over the games played.",
+	GlobalMethodRank:       "Each game awards 100 points to the best player, 99 to the second and so on down to 1 point for rank 100. The total is the sum over the games played.",
+	GlobalMethodRaw:        "The sum of every score value, without normalization. Lower-is-better games are left out.",
+}
+
+// rankPoints is the number of points awarded to the best player of a game by the rank method
+const rankPoints = 100
+
+var ErrInvalidGlobalMethod = errors.New("invalid method, expected percentile, zscore, rank or raw")
+
+// GlobalStanding is a player's position on the global leaderboard
+type GlobalStanding struct {
+	Rank       int
+	UserID     primitive.ObjectID
+	Username   string
+	Total      float64
+	Average    float64 // Total divided by the number of games played
+	TotalPlays int
+	GamePoints map[string]float64 // What each game contributed to the total
+}
+
+// playerGameResult summarizes a player's scores in one game
+type playerGameResult struct {
+	Owner primitive.ObjectID
+	Game  string
+	Best  float64
+	Sum   float64
+	Plays int
+}
+
+// GetGlobalLeaderboard ranks players across all games using the given method
+func GetGlobalLeaderboard(ctx context.Context, method string, limit int) ([]GlobalStanding, error) {
+	if _, ok := GlobalMethodDescriptions[method]; !ok {
+		return nil, ErrInvalidGlobalMethod
+	}
+
+	gameTypes, err := allGameTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results, err := playerGameResults(ctx, gameTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Score every player's result in every game
+	byGame := make(map[string][]*playerGameResult)
+	for i := range results {
+		byGame[results[i].Game] = append(byGame[results[i].Game], &results[i])
+	}
+
+	standings := make(map[primitive.ObjectID]*GlobalStanding)
+	for game, gameResults := range byGame {
+		gameType, known := gameTypes[game]
+		if !known {
+			continue
+		}
+		points := gamePoints(method, gameType, gameResults)
+
+		for i, result := range gameResults {
+			standing, ok := standings[result.Owner]
+			if !ok {
+				standing = &GlobalStanding{UserID: result.Owner, GamePoints: map[string]float64{}}
+				standings[result.Owner] = standing
+			}
+			standing.TotalPlays += result.Plays
+			if points[i] != nil {
+				standing.GamePoints[game] = *points[i]
+				standing.Total += *points[i]
+			}
+		}
+	}
+
+	ranked := make([]*GlobalStanding, 0, len(standings))
+	for _, standing := range standings {
+		if len(standing.GamePoints) > 0 {
+			standing.Average = standing.Total / float64(len(standing.GamePoints))
+		}
+		ranked = append(ranked, standing)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Total != ranked[j].Total {
+			return ranked[i].Total > ranked[j].Total
+		}
+		return ranked[i].UserID.Hex() < ranked[j].UserID.Hex()
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	// Populate usernames in a single query, skipping deleted users
+	usernames, err := usernamesByID(ctx, ranked)
+	if err != nil {
+		return nil, err
+	}
+
+	leaderboard := []GlobalStanding{}
+	for _, standing := range ranked {
+		username, ok := usernames[standing.UserID]
+		if !ok {
+			continue
+		}
+		standing.Username = username
+		standing.Rank = len(leaderboard) + 1
+		leaderboard = append(leaderboard, *standing)
+	}
+	return leaderboard, nil
+}
+
+// gamePoints returns what each result contributes to the global total under
+// the method, or nil for results that do not count. Ranking methods reorder
+// results best first, and the returned points follow that order.
+func gamePoints(method string, gameType models.GameType, results []*playerGameResult) []*float64 {
+	points := make([]*float64, len(results))
+
+	if method == GlobalMethodRaw {
+		if gameType.LowerIsBetter() {
+			return points
+		}
+		for i, result := range results {
+			sum := result.Sum
+			points[i] = &sum
+		}
+		return points
+	}
+
+	// Order the players best first so ranks and percentiles follow the game's sort direction
+	sort.SliceStable(results, func(i, j int) bool {
+		if gameType.LowerIsBetter() {
+			return results[i].Best < results[j].Best
+		}
+		return results[i].Best > results[j].Best
+	})
+
+	n := len(results)
+	mean, stddev := meanAndStdDev(results)
+
+	rank := 1
+	for i, result := range results {
+		// Players with the same best share a rank
+		if i > 0 && result.Best != results[i-1].Best {
+			rank = i + 1
+		}
+
+		var value float64
+		switch method {
+		case GlobalMethodPercentile:
+			value = 100
+			if n > 1 {
+				value = 100 * float64(n-rank) / float64(n-1)
+			}
+		case GlobalMethodZScore:
+			if stddev > 0 {
+				value = (result.Best - mean) / stddev
+				if gameType.LowerIsBetter() {
+					value = -value
+				}
+			}
+		case GlobalMethodRank:
+			value = math.Max(0, float64(rankPoints-rank+1))
+		}
+
+		value = math.Round(value*100) / 100
+		points[i] = &value
+	}
+	return points
+}
+
+// meanAndStdDev returns the mean and population standard deviation of the best scores
+func meanAndStdDev(results []*playerGameResult) (float64, float64) {
+	if len(results) == 0 {
+		return 0, 0
+	}
+
+	var sum float64
+	for _, result := range results {
+		sum += result.Best
+	}
+	mean := sum / float64(len(results))
+
+	var squares float64
+	for _, result := range results {
+		squares += (result.Best - mean) * (result.Best - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(results)))
+}
+
+// playerGameResults aggregates every player's best score, score sum and play
+// count per game, taking each game's sort direction into account
+func playerGameResults(ctx context.Context, gameTypes map[string]models.GameType) ([]playerGameResult, error) {
+	lowerIsBetterGames := bson.A{}
+	for code, gameType := range gameTypes {
+		if gameType.LowerIsBetter() {
+			lowerIsBetterGames = append(lowerIsBetterGames, code)
+		}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"owner": "$owner", "game": "$game"},
+			"max":   bson.M{"$max": "$value"},
+			"min":   bson.M{"$min": "$value"},
+			"sum":   bson.M{"$sum": "$value"},
+			"plays": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":   0,
+			"owner": "$_id.owner",
+			"game":  "$_id.game",
+			"best": bson.M{"$cond": bson.A{
+				bson.M{"$in": bson.A{"$_id.game", lowerIsBetterGames}}, "$min", "$max",
+			}},
+			"sum":   "$sum",
+			"plays": "$plays",
+		}}},
+	}
+
+	cursor, err := db.ScoreColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []playerGameResult
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// allGameTypes returns every game type keyed by game code
+func allGameTypes(ctx context.Context) (map[string]models.GameType, error) {
+	cursor, err := db.GameTypeColl.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var gameTypes []models.GameType
+	if err := cursor.All(ctx, &gameTypes); err != nil {
+		return nil, err
+	}
+
+	byCode := make(map[string]models.GameType, len(gameTypes))
+	for _, gameType := range gameTypes {
+		byCode[gameType.GameCode] = gameType
+	}
+	return byCode, nil
+}
+
+// usernamesByID looks up the usernames of the players on a leaderboard
+func usernamesByID(ctx context.Context, standings []*GlobalStanding) (map[primitive.ObjectID]string, error) {
+	ids := make([]primitive.ObjectID, 0, len(standings))
+	for _, standing := range standings {
+		ids = append(ids, standing.UserID)
+	}
+
+	cursor, err := db.UserColl.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	usernames := make(map[primitive.ObjectID]string, len(users))
+	for _, user := range users {
+		usernames[user.ID] = user.Username
+	}
+	return usernames, nil
+}
@@ -0,0 +1,354 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"netgames-go-server/db"
+	"netgames-go-server/models"
+)
+
+// MaterializedLeaderboardSize is the number of entries kept per materialized leaderboard
+const MaterializedLeaderboardSize = 100
+
+// ScoreFilter builds the scores filter for a game and timeframe
+func ScoreFilter(gameCode string, timeFrame TimeFrame) bson.M {
+	filter := bson.M{"game": gameCode}
+	if !timeFrame.Season.IsZero() {
+		filter["season"] = timeFrame.Season
+	}
+	if !timeFrame.Start.IsZero() {
+		filter["createdAt"] = bson.M{"$gte": timeFrame.Start}
+	}
+	return filter
+}
+
+// TopEntries ranks the scores matching the filter. With perPlayer set, only
+// each player's best run is ranked so a player appears at most once.
+func TopEntries(ctx context.Context, filter bson.M, gameType models.GameType, limit int, perPlayer bool) ([]models.LeaderboardEntry, error) {
+	return rankedEntries(ctx, filter, gameType, 0, limit, perPlayer)
+}
+
+// rankedEntries returns limit ranked entries after skipping the first skip ranks
+func rankedEntries(ctx context.Context, filter bson.M, gameType models.GameType, skip, limit int, perPlayer bool) ([]models.LeaderboardEntry, error) {
+	cursor, err := db.ScoreColl.Aggregate(ctx, rankingPipeline(filter, gameType, skip, limit, perPlayer))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []models.LeaderboardEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].Rank = skip + i + 1
+	}
+	return entries, nil
+}
+
+// rankingPipeline builds the aggregation behind rankedEntries. Deleted users
+// are dropped before skipping, so ranks match the counts of CountPlayers.
+func rankingPipeline(filter bson.M, gameType models.GameType, skip, limit int, perPlayer bool) mongo.Pipeline {
+	bestFirst := bestFirstSort(gameType)
+
+	pipeline := mongo.Pipeline{
+		// Match the scores to rank and put the best (and earliest) runs first
+		{{Key: "$match", Value: filter}},
+		{{Key: "$sort", Value: bestFirst}},
+	}
+	if perPlayer {
+		pipeline = append(pipeline,
+			// Keep one run per player and rank the players' best runs
+			bson.D{{Key: "$group", Value: bson.M{
+				"_id":  "$owner",
+				"best": bson.M{"$first": "$$ROOT"},
+			}}},
+			bson.D{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$best"}}},
+			bson.D{{Key: "$sort", Value: bestFirst}},
+		)
+	}
+	pipeline = append(pipeline,
+		// Populate the owner in the same round trip, skipping deleted users
+		bson.D{{Key: "$lookup", Value: bson.M{
+			"from":         "users",
+			"localField":   "owner",
+			"foreignField": "_id",
+			"as":           "ownerDoc",
+		}}},
+		bson.D{{Key: "$unwind", Value: "$ownerDoc"}},
+	)
+	if skip > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$skip", Value: int64(skip)}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$limit", Value: int64(limit)}},
+		bson.D{{Key: "$project", Value: bson.M{
+			"_id":        0,
+			"score_id":   "$_id",
+			"user_id":    "$owner",
+			"username":   "$ownerDoc.username",
+			"score":      "$value",
+			"metadata":   "$metadata",
+			"created_at": "$createdAt",
+		}}},
+	)
+	return pipeline
+}
+
+// bestFirstSort orders scores best first, breaking ties by the earliest run
+func bestFirstSort(gameType models.GameType) bson.D {
+	return bson.D{{Key: "value", Value: gameType.SortOrder()}, {Key: "createdAt", Value: 1}}
+}
+
+// PlayerStanding is a player's position on a game leaderboard
+type PlayerStanding struct {
+	Entry        models.LeaderboardEntry
+	TotalPlayers int64
+	Percentile   float64 // Share of players ranked at or below the player
+	Above        []models.LeaderboardEntry
+	Below        []models.LeaderboardEntry
+}
+
+// GetPlayerStanding ranks a player's best score among every player's best
+// score matching the filter, together with up to around neighbours on each
+// side. It returns nil if the player has no matching scores.
+func GetPlayerStanding(ctx context.Context, filter bson.M, gameType models.GameType, userID primitive.ObjectID, around int) (*PlayerStanding, error) {
+	// Find the player's best (and earliest) run
+	playerFilter := bson.M{"owner": userID}
+	for key, value := range filter {
+		playerFilter[key] = value
+	}
+
+	var best models.Score
+	err := db.ScoreColl.FindOne(ctx, playerFilter, options.FindOne().SetSort(bestFirstSort(gameType))).Decode(&best)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// Count the players whose best run ranks ahead, using the same tie break
+	// as the leaderboard itself
+	betterOp := "$gt"
+	if gameType.LowerIsBetter() {
+		betterOp = "$lt"
+	}
+	ahead, err := CountPlayers(ctx, filter, gameType, bson.M{"$or": bson.A{
+		bson.M{"value": bson.M{betterOp: best.Value}},
+		bson.M{"value": best.Value, "createdAt": bson.M{"$lt": best.CreatedAt}},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	total, err := CountPlayers(ctx, filter, gameType, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		// The player's own user was deleted
+		return nil, nil
+	}
+
+	rank := int(ahead) + 1
+	aboveSkip := rank - 1 - around
+	if aboveSkip < 0 {
+		aboveSkip = 0
+	}
+
+	// Load the neighbours and the player's own entry in a single window
+	window, err := rankedEntries(ctx, filter, gameType, aboveSkip, rank-aboveSkip+around, true)
+	if err != nil {
+		return nil, err
+	}
+
+	standing := &PlayerStanding{
+		TotalPlayers: total,
+		Percentile:   math.Round(float64(total-ahead)/float64(total)*10000) / 100,
+		Above:        []models.LeaderboardEntry{},
+		Below:        []models.LeaderboardEntry{},
+	}
+	found := false
+	for _, entry := range window {
+		switch {
+		case entry.Rank < rank:
+			standing.Above = append(standing.Above, entry)
+		case entry.Rank > rank:
+			standing.Below = append(standing.Below, entry)
+		default:
+			standing.Entry = entry
+			found = entry.UserID == userID
+		}
+	}
+
+	// The player's own user was deleted, so they are not on the leaderboard
+	if !found {
+		return nil, nil
+	}
+	return standing, nil
+}
+
+// CountPlayers counts the players whose best run matching the filter also
+// matches bestFilter. Deleted users are not counted.
+func CountPlayers(ctx context.Context, filter bson.M, gameType models.GameType, bestFilter bson.M) (int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$sort", Value: bestFirstSort(gameType)}},
+		// Keep each player's best run
+		{{Key: "$group", Value: bson.M{
+			"_id":       "$owner",
+			"value":     bson.M{"$first": "$value"},
+			"createdAt": bson.M{"$first": "$createdAt"},
+		}}},
+		// Skip deleted users, as the leaderboard itself does
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "users",
+			"localField":   "_id",
+			"foreignField": "_id",
+			"as":           "ownerDoc",
+		}}},
+		{{Key: "$match", Value: bson.M{"ownerDoc.0": bson.M{"$exists": true}}}},
+		{{Key: "$match", Value: bestFilter}},
+		{{Key: "$count", Value: "players"}},
+	}
+
+	cursor, err := db.ScoreColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []struct {
+		Players int64 `bson:"players"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return 0, err
+	}
+	if len(result) == 0 {
+		return 0, nil
+	}
+	return result[0].Players, nil
+}
+
+// GetMaterializedLeaderboard returns the stored leaderboard for a game and
+// timeframe, or nil if it is missing or older than the timeframe's current period
+func GetMaterializedLeaderboard(ctx context.Context, gameCode string, timeFrame TimeFrame) (*models.Leaderboard, error) {
+	var leaderboard models.Leaderboard
+	err := db.LeaderboardColl.FindOne(ctx, bson.M{"game_type": gameCode, "timeframe": timeFrame.Name}).Decode(&leaderboard)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// A board refreshed before the current day, week or month started still
+	// describes the previous period
+	if leaderboard.LastUpdated.Before(timeFrame.Start) {
+		return nil, nil
+	}
+	return &leaderboard, nil
+}
+
+// RefreshLeaderboard recomputes and stores the UTC leaderboard for a game and
+// timeframe, unless a refresh that started later already stored a newer one
+func RefreshLeaderboard(ctx context.Context, gameType models.GameType, timeFrameName string) (models.Leaderboard, error) {
+	now := time.Now()
+	timeFrame, err := ParseTimeFrame(timeFrameName, "", now)
+	if err != nil {
+		return models.Leaderboard{}, err
+	}
+
+	entries, err := TopEntries(ctx, ScoreFilter(gameType.GameCode, timeFrame), gameType, MaterializedLeaderboardSize, true)
+	if err != nil {
+		return models.Leaderboard{}, err
+	}
+
+	// Refreshes run concurrently, so only replace a board computed from older
+	// data. When a newer board is stored the upsert hits the unique index.
+	key := bson.M{"game_type": gameType.GameCode, "timeframe": timeFrame.Name}
+	var leaderboard models.Leaderboard
+	err = db.LeaderboardColl.FindOneAndUpdate(
+		ctx,
+		bson.M{"game_type": gameType.GameCode, "timeframe": timeFrame.Name, "last_updated": bson.M{"$lt": now}},
+		bson.M{"$set": bson.M{"entries": entries, "last_updated": now}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&leaderboard)
+	if mongo.IsDuplicateKeyError(err) {
+		err = db.LeaderboardColl.FindOne(ctx, key).Decode(&leaderboard)
+	}
+	if err != nil {
+		return models.Leaderboard{}, err
+	}
+	return leaderboard, nil
+}
+
+// RefreshGameLeaderboards recomputes every materialized timeframe of a game
+func RefreshGameLeaderboards(ctx context.Context, gameType models.GameType) error {
+	for _, timeFrame := range MaterializedTimeFrames {
+		if _, err := RefreshLeaderboard(ctx, gameType, timeFrame); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RefreshGameLeaderboardsAsync refreshes a game's leaderboards in the background,
+// e.g. after a score was posted
+func RefreshGameLeaderboardsAsync(gameType models.GameType) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := RefreshGameLeaderboards(ctx, gameType); err != nil {
+			log.Printf("Error refreshing leaderboards for %s: %v", gameType.GameCode, err)
+		}
+	}()
+}
+
+// RebuildAllLeaderboards recomputes the materialized leaderboards of every game
+func RebuildAllLeaderboards(ctx context.Context) error {
+	cursor, err := db.GameTypeColl.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var gameTypes []models.GameType
+	if err := cursor.All(ctx, &gameTypes); err != nil {
+		return err
+	}
+
+	for _, gameType := range gameTypes {
+		if err := RefreshGameLeaderboards(ctx, gameType); err != nil {
+			return err
+		}
+		log.Printf("Rebuilt leaderboards for %s", gameType.GameCode)
+	}
+	return nil
+}
+
+// StartLeaderboardScheduler periodically rebuilds all leaderboards so that a
+// new day, week or month starts empty even when nobody posts new scores
+func StartLeaderboardScheduler(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			if err := RebuildAllLeaderboards(ctx); err != nil {
+				log.Printf("Error rebuilding leaderboards: %v", err)
+			}
+			cancel()
+		}
+	}()
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"netgames-go-server/db"
+	"netgames-go-server/models"
+)
+
+// useTestDatabase points the collections at a scratch database on the server
+// in MONGODB_TEST_URI, and skips the test when it is not set
+func useTestDatabase(t *testing.T) context.Context {
+	t.Helper()
+	uri := os.Getenv("MONGODB_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGODB_TEST_URI is not set")
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	database := client.Database(fmt.Sprintf("netgames_test_%d", time.Now().UnixNano()))
+	db.UserColl = database.Collection("users")
+	db.ScoreColl = database.Collection("scores")
+	t.Cleanup(func() {
+		database.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+	return ctx
+}
+
+// stageIndex returns the position of the first stage of the given kind, or -1
+func stageIndex(pipeline mongo.Pipeline, stage string) int {
+	for i, d := range pipeline {
+		if len(d) > 0 && d[0].Key == stage {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestRankingPipelineDropsDeletedUsersBeforeSkipping(t *testing.T) {
+	gameType := models.GameType{GameCode: "pong", SortDirection: models.SortDescending}
+
+	for _, perPlayer := range []bool{false, true} {
+		pipeline := rankingPipeline(bson.M{"game": "pong"}, gameType, 5, 10, perPlayer)
+
+		unwind := stageIndex(pipeline, "$unwind")
+		skip := stageIndex(pipeline, "$skip")
+		limit := stageIndex(pipeline, "$limit")
+		if unwind < 0 || skip < 0 || limit < 0 {
+			t.Fatalf("perPlayer %v: pipeline is missing a stage: %v", perPlayer, pipeline)
+		}
+		if unwind > skip || unwind > limit {
+			t.Errorf("perPlayer %v: deleted users are dropped at stage %d, after $skip (%d) or $limit (%d)", perPlayer, unwind, skip, limit)
+		}
+	}
+}
+
+func TestBestFirstSort(t *testing.T) {
+	tests := []struct {
+		name      string
+		direction string
+		want      int
+	}{
+		{"higher is better", models.SortDescending, -1},
+		{"lower is better", models.SortAscending, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sort := bestFirstSort(models.GameType{SortDirection: tt.direction})
+			if len(sort) != 2 || sort[0].Key != "value" || sort[0].Value != tt.want {
+				t.Fatalf("bestFirstSort(%s) = %v, want value %d first", tt.direction, sort, tt.want)
+			}
+			if sort[1].Key != "createdAt" || sort[1].Value != 1 {
+				t.Errorf("bestFirstSort(%s) = %v, want ties broken by the earliest run", tt.direction, sort)
+			}
+		})
+	}
+}
+
+func TestGetPlayerStandingSkipsDeletedUsers(t *testing.T) {
+	ctx := useTestDatabase(t)
+	gameType := models.GameType{GameCode: "pong", SortDirection: models.SortDescending}
+	now := time.Now()
+
+	// The deleted user ranks first, ahead of both remaining players
+	deleted, second, player := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	for i, owner := range []primitive.ObjectID{deleted, second, player} {
+		if _, err := db.ScoreColl.InsertOne(ctx, models.Score{
+			Owner:     owner,
+			Game:      gameType.GameCode,
+			Value:     30 - 10*i,
+			CreatedAt: now,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, user := range []primitive.ObjectID{second, player} {
+		if _, err := db.UserColl.InsertOne(ctx, bson.M{"_id": user, "username": user.Hex()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	standing, err := GetPlayerStanding(ctx, ScoreFilter(gameType.GameCode, TimeFrame{}), gameType, player, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if standing == nil {
+		t.Fatal("GetPlayerStanding() = nil, want the player's standing")
+	}
+	if standing.Entry.UserID != player || standing.Entry.Rank != 2 {
+		t.Errorf("entry = %v at rank %d, want %v at rank 2", standing.Entry.UserID, standing.Entry.Rank, player)
+	}
+	if standing.TotalPlayers != 2 {
+		t.Errorf("total players = %d, want 2", standing.TotalPlayers)
+	}
+}
+
+func TestGetPlayerStandingRanksLowestFirst(t *testing.T) {
+	ctx := useTestDatabase(t)
+	gameType := models.GameType{GameCode: "sprint", SortDirection: models.SortAscending}
+	now := time.Now()
+
+	// The fastest time ranks first, and the player's best is their lowest time
+	fastest, player, slowest := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	scores := []models.Score{
+		{Owner: fastest, Value: 10},
+		{Owner: player, Value: 40},
+		{Owner: player, Value: 20},
+		{Owner: slowest, Value: 30},
+	}
+	for _, score := range scores {
+		score.Game = gameType.GameCode
+		score.CreatedAt = now
+		if _, err := db.ScoreColl.InsertOne(ctx, score); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, user := range []primitive.ObjectID{fastest, player, slowest} {
+		if _, err := db.UserColl.InsertOne(ctx, bson.M{"_id": user, "username": user.Hex()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	standing, err := GetPlayerStanding(ctx, ScoreFilter(gameType.GameCode, TimeFrame{}), gameType, player, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if standing == nil {
+		t.Fatal("GetPlayerStanding() = nil, want the player's standing")
+	}
+	if standing.Entry.Rank != 2 || standing.Entry.Score != 20 {
+		t.Errorf("entry = %d at rank %d, want 20 at rank 2", standing.Entry.Score, standing.Entry.Rank)
+	}
+	if len(standing.Above) != 1 || standing.Above[0].UserID != fastest {
+		t.Errorf("above = %v, want the fastest player", standing.Above)
+	}
+	if len(standing.Below) != 1 || standing.Below[0].UserID != slowest {
+		t.Errorf("below = %v, want the slowest player", standing.Below)
+	}
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"netgames-go-server/db"
+	"netgames-go-server/models"
+)
+
+// playDayFormat is the layout of the UTC days used for streaks
+const playDayFormat = "2006-01-02"
+
+// ProfileStats computes the stats shown on a user's profile
+func ProfileStats(ctx context.Context, userID primitive.ObjectID, now time.Time) (models.ProfileStats, error) {
+	stats := models.ProfileStats{Games: []models.GameStats{}}
+
+	gameTypes, err := allGameTypes(ctx)
+	if err != nil {
+		return stats, err
+	}
+
+	games, err := userGameStats(ctx, userID, gameTypes)
+	if err != nil {
+		return stats, err
+	}
+
+	favoritePlays := 0
+	for _, game := range games {
+		stats.TotalGamesPlayed += game.Plays
+		if game.Plays > favoritePlays {
+			stats.FavoriteGame, favoritePlays = game.GameCode, game.Plays
+		}
+		if stats.FirstPlayedAt == nil || game.FirstPlayedAt.Before(*stats.FirstPlayedAt) {
+			first := game.FirstPlayedAt
+			stats.FirstPlayedAt = &first
+		}
+		if stats.LastPlayedAt == nil || game.LastPlayedAt.After(*stats.LastPlayedAt) {
+			last := game.LastPlayedAt
+			stats.LastPlayedAt = &last
+		}
+	}
+	stats.Games = games
+
+	stats.HighestScore, err = highestScore(ctx, userID, gameTypes)
+	if err != nil {
+		return stats, err
+	}
+
+	days, err := playDays(ctx, userID)
+	if err != nil {
+		return stats, err
+	}
+	stats.CurrentStreak, stats.LongestStreak = playStreaks(days, now)
+
+	stats.TotalAchievements, err = db.UserAchievementColl.CountDocuments(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return stats, err
+	}
+	stats.AchievementScore, err = AchievementScore(ctx, userID)
+	if err != nil {
+		return stats, err
+	}
+	return stats, nil
+}
+
+// userGameStats summarizes a user's plays per game, most played first
+func userGameStats(ctx context.Context, userID primitive.ObjectID, gameTypes map[string]models.GameType) ([]models.GameStats, error) {
+	cursor, err := db.ScoreColl.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"owner": userID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$game",
+			"plays": bson.M{"$sum": 1},
+			"max":   bson.M{"$max": "$value"},
+			"min":   bson.M{"$min": "$value"},
+			"first": bson.M{"$min": "$createdAt"},
+			"last":  bson.M{"$max": "$createdAt"},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Game  string    `bson:"_id"`
+		Plays int       `bson:"plays"`
+		Max   int       `bson:"max"`
+		Min   int       `bson:"min"`
+		First time.Time `bson:"first"`
+		Last  time.Time `bson:"last"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	games := make([]models.GameStats, 0, len(results))
+	for _, result := range results {
+		game := models.GameStats{
+			GameCode:      result.Game,
+			GameName:      result.Game,
+			Plays:         result.Plays,
+			BestScore:     result.Max,
+			FirstPlayedAt: result.First,
+			LastPlayedAt:  result.Last,
+		}
+		if gameType, ok := gameTypes[result.Game]; ok {
+			game.GameName = gameType.Name
+			if gameType.LowerIsBetter() {
+				game.BestScore = result.Min
+			}
+		}
+		games = append(games, game)
+	}
+
+	// Ties on plays go to the game code so the favourite game is stable
+	sort.Slice(games, func(i, j int) bool {
+		if games[i].Plays != games[j].Plays {
+			return games[i].Plays > games[j].Plays
+		}
+		return games[i].GameCode < games[j].GameCode
+	})
+	return games, nil
+}
+
+// highestScore returns a user's best score over the higher-is-better games,
+// since scores of lower-is-better games cannot be compared with them
+func highestScore(ctx context.Context, userID primitive.ObjectID, gameTypes map[string]models.GameType) (int, error) {
+	games := bson.A{}
+	for code, gameType := range gameTypes {
+		if !gameType.LowerIsBetter() {
+			games = append(games, code)
+		}
+	}
+
+	cursor, err := db.ScoreColl.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"owner": userID, "game": bson.M{"$in": games}}}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "max": bson.M{"$max": "$value"}}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []struct {
+		Max int `bson:"max"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return 0, err
+	}
+	if len(result) == 0 {
+		return 0, nil
+	}
+	return result[0].Max, nil
+}
+
+// playDays returns the distinct UTC days on which a user posted a score, in order
+func playDays(ctx context.Context, userID primitive.ObjectID) ([]time.Time, error) {
+	cursor, err := db.ScoreColl.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"owner": userID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$createdAt"}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Day string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	days := make([]time.Time, 0, len(results))
+	for _, result := range results {
+		day, err := time.Parse(playDayFormat, result.Day)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// playStreaks returns the current and longest runs of consecutive play days.
+// The current streak is still alive if the last play was today or yesterday.
+func playStreaks(days []time.Time, now time.Time) (int, int) {
+	longest, run := 0, 0
+	for i, day := range days {
+		if i > 0 && day.Sub(days[i-1]) == 24*time.Hour {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+
+	if len(days) == 0 {
+		return 0, 0
+	}
+	today := now.UTC().Truncate(24 * time.Hour)
+	if today.Sub(days[len(days)-1]) > 24*time.Hour {
+		return 0, longest
+	}
+	return run, longest
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlayStreaks(t *testing.T) {
+	now := time.Date(2026, 6, 10, 15, 0, 0, 0, time.UTC)
+	day := func(date string) time.Time {
+		parsed, err := time.Parse(playDayFormat, date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+	days := func(dates ...string) []time.Time {
+		parsed := make([]time.Time, 0, len(dates))
+		for _, date := range dates {
+			parsed = append(parsed, day(date))
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name        string
+		days        []time.Time
+		wantCurrent int
+		wantLongest int
+	}{
+		{"never played", nil, 0, 0},
+		{"played today", days("2026-06-10"), 1, 1},
+		{"played yesterday", days("2026-06-08", "2026-06-09"), 2, 2},
+		{"streak broken", days("2026-06-06", "2026-06-07", "2026-06-08"), 0, 3},
+		{"longest in the past", days("2026-06-01", "2026-06-02", "2026-06-03", "2026-06-09", "2026-06-10"), 2, 3},
+		{"gap restarts the run", days("2026-06-07", "2026-06-09", "2026-06-10"), 2, 2},
+		{"across a month", days("2026-05-30", "2026-05-31", "2026-06-01"), 0, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, longest := playStreaks(tt.days, now)
+			if current != tt.wantCurrent || longest != tt.wantLongest {
+				t.Errorf("playStreaks() = %d, %d, want %d, %d", current, longest, tt.wantCurrent, tt.wantLongest)
+			}
+		})
+	}
+}
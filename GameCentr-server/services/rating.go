@@ -0,0 +1,427 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"netgames-go-server/db"
+	"netgames-go-server/models"
+)
+
+// Glicko parameters. Every posted score is rated as a set of matches against
+// the game's most recent scores by other players: beating a score is a win,
+// matching it a draw.
+const (
+	DefaultRating        = 1500.0
+	DefaultDeviation     = 350.0
+	MinDeviation         = 30.0
+	ProvisionalDeviation = 110.0 // Ratings less certain than this are provisional
+
+	// RecentScoreWindow is the number of recent scores a new score is rated against
+	RecentScoreWindow = 50
+
+	// Inactive players' deviation grows back from 50 to 350 over about 100 rating periods
+	ratingPeriod        = 24 * time.Hour
+	deviationGrowthRate = 34.6
+)
+
+// maxRatingAttempts bounds how often a score is re-rated after losing a race
+// with another rating update of the same player
+const maxRatingAttempts = 5
+
+// ratingWriteBatch is the number of ratings written per bulk write by RecomputeRatings
+const ratingWriteBatch = 1000
+
+// duplicateKeyCode is the MongoDB error code of a unique index violation
+const duplicateKeyCode = 11000
+
+var ErrRatingConflict = errors.New("rating kept changing while it was updated")
+
+// glickoQ is the Glicko scaling constant ln(10)/400
+var glickoQ = math.Ln10 / 400
+
+// ratingKey identifies a player's rating in a game
+type ratingKey struct {
+	UserID   primitive.ObjectID
+	GameCode string
+}
+
+// NewRating returns the rating of a player who has not played the game yet
+func NewRating(userID primitive.ObjectID, gameCode string) models.Rating {
+	return models.Rating{
+		UserID:       userID,
+		GameCode:     gameCode,
+		Rating:       DefaultRating,
+		Deviation:    DefaultDeviation,
+		Conservative: DefaultRating - 2*DefaultDeviation,
+	}
+}
+
+// CurrentDeviation returns the rating's deviation at now, which grows while
+// the player does not play
+func CurrentDeviation(rating models.Rating, now time.Time) float64 {
+	if rating.LastPlayedAt.IsZero() {
+		return rating.Deviation
+	}
+	periods := now.Sub(rating.LastPlayedAt).Hours() / ratingPeriod.Hours()
+	if periods <= 0 {
+		return rating.Deviation
+	}
+	return math.Min(math.Sqrt(rating.Deviation*rating.Deviation+deviationGrowthRate*deviationGrowthRate*periods), DefaultDeviation)
+}
+
+// IsProvisional reports whether a rating is still too uncertain to be trusted
+func IsProvisional(rating models.Rating, now time.Time) bool {
+	return CurrentDeviation(rating, now) > ProvisionalDeviation
+}
+
+// rateScore updates the player's rating with a score rated against recent
+// scores and the current ratings of their owners
+func rateScore(rating models.Rating, gameType models.GameType, score models.Score, recent []models.Score, ratings map[ratingKey]models.Rating) models.Rating {
+	deviation := CurrentDeviation(rating, score.CreatedAt)
+
+	var variance, improvement float64
+	for _, opponentScore := range recent {
+		opponent, ok := ratings[ratingKey{opponentScore.Owner, gameType.GameCode}]
+		if !ok {
+			opponent = NewRating(opponentScore.Owner, gameType.GameCode)
+		}
+
+		outcome := 0.5
+		if gameType.IsBetter(score.Value, opponentScore.Value) {
+			outcome = 1
+		} else if gameType.IsBetter(opponentScore.Value, score.Value) {
+			outcome = 0
+		}
+
+		g := glickoG(CurrentDeviation(opponent, score.CreatedAt))
+		expected := 1 / (1 + math.Pow(10, -g*(rating.Rating-opponent.Rating)/400))
+		variance += g * g * expected * (1 - expected)
+		improvement += g * (outcome - expected)
+	}
+
+	if variance > 0 {
+		precision := 1/(deviation*deviation) + glickoQ*glickoQ*variance
+		rating.Rating += glickoQ / precision * improvement
+		deviation = math.Sqrt(1 / precision)
+	}
+
+	rating.Deviation = math.Max(deviation, MinDeviation)
+	rating.Conservative = rating.Rating - 2*rating.Deviation
+	rating.Plays++
+	rating.LastPlayedAt = score.CreatedAt
+	rating.UpdatedAt = time.Now()
+	return rating
+}
+
+// glickoG reduces the weight of matches against opponents with uncertain ratings
+func glickoG(deviation float64) float64 {
+	return 1 / math.Sqrt(1+3*glickoQ*glickoQ*deviation*deviation/(math.Pi*math.Pi))
+}
+
+// UpdateRating rates a newly posted score and stores the owner's new rating.
+// Concurrent updates of the same rating are retried rather than lost.
+func UpdateRating(ctx context.Context, gameType models.GameType, score models.Score) (models.Rating, error) {
+	// The most recent scores of other players make up the field
+	cursor, err := db.ScoreColl.Find(ctx, bson.M{
+		"game":      gameType.GameCode,
+		"owner":     bson.M{"$ne": score.Owner},
+		"createdAt": bson.M{"$lte": score.CreatedAt},
+	}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(RecentScoreWindow))
+	if err != nil {
+		return models.Rating{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var recent []models.Score
+	if err := cursor.All(ctx, &recent); err != nil {
+		return models.Rating{}, err
+	}
+
+	// Another post by the same player may store its rating between reading and
+	// writing it, in which case the score is rated again on top of that rating
+	for attempt := 0; attempt < maxRatingAttempts; attempt++ {
+		player, rated, err := rateAgainst(ctx, gameType, score, recent)
+		if err != nil {
+			return models.Rating{}, err
+		}
+		if !rated {
+			return player, nil
+		}
+
+		stored, err := storeRating(ctx, player)
+		if err != nil {
+			return models.Rating{}, err
+		}
+		if stored {
+			return player, nil
+		}
+	}
+	return models.Rating{}, ErrRatingConflict
+}
+
+// rateAgainst loads the current ratings of the player and the opponents and
+// rates the score with them. It reports false, returning the rating as is, when
+// a recompute already replayed the score into the rating.
+func rateAgainst(ctx context.Context, gameType models.GameType, score models.Score, recent []models.Score) (models.Rating, bool, error) {
+	userIDs := []primitive.ObjectID{score.Owner}
+	for _, opponentScore := range recent {
+		userIDs = append(userIDs, opponentScore.Owner)
+	}
+	ratingCursor, err := db.RatingColl.Find(ctx, bson.M{"game_code": gameType.GameCode, "user_id": bson.M{"$in": userIDs}})
+	if err != nil {
+		return models.Rating{}, false, err
+	}
+	defer ratingCursor.Close(ctx)
+
+	var stored []models.Rating
+	if err := ratingCursor.All(ctx, &stored); err != nil {
+		return models.Rating{}, false, err
+	}
+	ratings := make(map[ratingKey]models.Rating, len(stored))
+	for _, rating := range stored {
+		ratings[ratingKey{rating.UserID, rating.GameCode}] = rating
+	}
+
+	player, ok := ratings[ratingKey{score.Owner, gameType.GameCode}]
+	if !ok {
+		player = NewRating(score.Owner, gameType.GameCode)
+	}
+	if score.CreatedAt.Before(player.RecomputedAt) {
+		return player, false, nil
+	}
+	return rateScore(player, gameType, score, recent, ratings), true, nil
+}
+
+// storeRating writes a rated player's rating only if nobody changed it since
+// it was read, which the play count tells. It reports false on a conflict.
+func storeRating(ctx context.Context, player models.Rating) (bool, error) {
+	if player.Plays == 1 {
+		// First rating of the player in this game, the unique index catches races
+		_, err := db.RatingColl.InsertOne(ctx, player)
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	result, err := db.RatingColl.UpdateOne(
+		ctx,
+		bson.M{"user_id": player.UserID, "game_code": player.GameCode, "plays": player.Plays - 1},
+		bson.M{"$set": bson.M{
+			"rating":              player.Rating,
+			"deviation":           player.Deviation,
+			"conservative_rating": player.Conservative,
+			"plays":               player.Plays,
+			"last_played_at":      player.LastPlayedAt,
+			"updated_at":          player.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// UpdateRatingAsync rates a newly posted score in the background
+func UpdateRatingAsync(gameType models.GameType, score models.Score) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if _, err := UpdateRating(ctx, gameType, score); err != nil {
+			log.Printf("Error updating %s rating of user %s: %v", gameType.GameCode, score.Owner.Hex(), err)
+		}
+	}()
+}
+
+// GetUserRatings returns a player's ratings keyed by game code
+func GetUserRatings(ctx context.Context, userID primitive.ObjectID) (map[string]models.Rating, error) {
+	cursor, err := db.RatingColl.Find(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var ratings []models.Rating
+	if err := cursor.All(ctx, &ratings); err != nil {
+		return nil, err
+	}
+
+	byGame := make(map[string]models.Rating, len(ratings))
+	for _, rating := range ratings {
+		byGame[rating.GameCode] = rating
+	}
+	return byGame, nil
+}
+
+// TopRatings returns the highest rated players of a game, ranked by their
+// conservative rating so a lucky first game does not top the leaderboard
+func TopRatings(ctx context.Context, gameCode string, limit int) ([]models.Rating, error) {
+	cursor, err := db.RatingColl.Find(
+		ctx,
+		bson.M{"game_code": gameCode},
+		options.Find().SetSort(bson.D{{Key: "conservative_rating", Value: -1}}).SetLimit(int64(limit)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	ratings := []models.Rating{}
+	if err := cursor.All(ctx, &ratings); err != nil {
+		return nil, err
+	}
+	return ratings, nil
+}
+
+// RecomputeRatings rebuilds every rating by replaying all scores posted before
+// it started, in the order they were posted. Ratings that live updates changed
+// while it ran are kept as they are, with a count logged, so no play is lost;
+// running it again includes them.
+func RecomputeRatings(ctx context.Context) error {
+	gameTypes, err := allGameTypes(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Note the play count of every rating before starting, so that ratings
+	// changed by live updates since are not overwritten
+	versions, err := ratingVersions(ctx)
+	if err != nil {
+		return err
+	}
+	startedAt := time.Now()
+
+	cursor, err := db.ScoreColl.Find(
+		ctx,
+		bson.M{"createdAt": bson.M{"$lt": startedAt}},
+		options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	ratings := make(map[ratingKey]models.Rating)
+	recentByGame := make(map[string][]models.Score)
+	replayed := 0
+	for cursor.Next(ctx) {
+		var score models.Score
+		if err := cursor.Decode(&score); err != nil {
+			return err
+		}
+		gameType, ok := gameTypes[score.Game]
+		if !ok {
+			continue
+		}
+
+		// Rate against the most recent scores of other players, newest first
+		recentScores := recentByGame[score.Game]
+		var opponents []models.Score
+		for i := len(recentScores) - 1; i >= 0 && len(opponents) < RecentScoreWindow; i-- {
+			if recentScores[i].Owner != score.Owner {
+				opponents = append(opponents, recentScores[i])
+			}
+		}
+
+		key := ratingKey{score.Owner, score.Game}
+		player, ok := ratings[key]
+		if !ok {
+			player = NewRating(score.Owner, score.Game)
+		}
+		ratings[key] = rateScore(player, gameType, score, opponents, ratings)
+
+		// Keep enough history to find RecentScoreWindow scores of other players
+		recentScores = append(recentScores, score)
+		if len(recentScores) > 4*RecentScoreWindow {
+			recentScores = recentScores[len(recentScores)-4*RecentScoreWindow:]
+		}
+		recentByGame[score.Game] = recentScores
+		replayed++
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	// Replace the ratings in place so the collection stays complete while the
+	// recompute runs. A rating whose play count moved on since the start fails
+	// the filter, and the upsert then hits the unique index instead.
+	updates := make([]mongo.WriteModel, 0, len(ratings))
+	for key, rating := range ratings {
+		rating.UpdatedAt = startedAt
+		rating.RecomputedAt = startedAt
+		updates = append(updates, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"user_id": key.UserID, "game_code": key.GameCode, "plays": versions[key]}).
+			SetReplacement(rating).
+			SetUpsert(true))
+	}
+	changed := 0
+	for len(updates) > 0 {
+		batch := updates[:min(len(updates), ratingWriteBatch)]
+		_, err := db.RatingColl.BulkWrite(ctx, batch, options.BulkWrite().SetOrdered(false))
+		conflicts, err := ratingConflicts(err)
+		if err != nil {
+			return err
+		}
+		changed += conflicts
+		updates = updates[len(batch):]
+	}
+
+	// Drop the ratings no score backs anymore. Ratings updated live since the
+	// start are newer and stay.
+	if _, err := db.RatingColl.DeleteMany(ctx, bson.M{"updated_at": bson.M{"$lt": startedAt}}); err != nil {
+		return err
+	}
+
+	log.Printf("Replayed %d scores into %d ratings", replayed, len(ratings))
+	if changed > 0 {
+		log.Printf("Kept %d ratings that changed during the recompute, run it again to include them", changed)
+	}
+	return nil
+}
+
+// ratingVersions returns the play count of every stored rating
+func ratingVersions(ctx context.Context) (map[ratingKey]int, error) {
+	cursor, err := db.RatingColl.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"user_id": 1, "game_code": 1, "plays": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	versions := make(map[ratingKey]int)
+	for cursor.Next(ctx) {
+		var rating models.Rating
+		if err := cursor.Decode(&rating); err != nil {
+			return nil, err
+		}
+		versions[ratingKey{rating.UserID, rating.GameCode}] = rating.Plays
+	}
+	return versions, cursor.Err()
+}
+
+// ratingConflicts counts the writes of a bulk write that failed on the unique
+// index because the rating changed, returning any other error
+func ratingConflicts(err error) (int, error) {
+	if err == nil {
+		return 0, nil
+	}
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+		return 0, err
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Code != duplicateKeyCode {
+			return 0, err
+		}
+	}
+	return len(bulkErr.WriteErrors), nil
+}
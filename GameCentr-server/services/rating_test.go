@@ -0,0 +1,123 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"netgames-go-server/models"
+)
+
+func TestRateScore(t *testing.T) {
+	now := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	player := primitive.NewObjectID()
+	opponents := []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()}
+
+	descending := models.GameType{GameCode: "pong", SortDirection: models.SortDescending}
+	ascending := models.GameType{GameCode: "memorymatch", SortDirection: models.SortAscending}
+
+	recent := func(values ...int) []models.Score {
+		scores := make([]models.Score, 0, len(values))
+		for i, value := range values {
+			scores = append(scores, models.Score{Owner: opponents[i], Value: value})
+		}
+		return scores
+	}
+
+	tests := []struct {
+		name       string
+		gameType   models.GameType
+		value      int
+		recent     []models.Score
+		wantChange int // Sign of the rating change
+	}{
+		{"beats everyone", descending, 50, recent(10, 20, 30), 1},
+		{"loses to everyone", descending, 5, recent(10, 20, 30), -1},
+		{"draws against equals", descending, 20, recent(20, 20), 0},
+		{"lower is better", ascending, 5, recent(10, 20, 30), 1},
+		{"lower is better and slower", ascending, 50, recent(10, 20, 30), -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rating := NewRating(player, tt.gameType.GameCode)
+			score := models.Score{Owner: player, Value: tt.value, CreatedAt: now}
+
+			got := rateScore(rating, tt.gameType, score, tt.recent, map[ratingKey]models.Rating{})
+
+			change := got.Rating - rating.Rating
+			switch {
+			case tt.wantChange > 0 && change <= 0, tt.wantChange < 0 && change >= 0, tt.wantChange == 0 && math.Abs(change) > 1e-9:
+				t.Errorf("rating changed by %v, want sign %d", change, tt.wantChange)
+			}
+			if got.Deviation >= rating.Deviation {
+				t.Errorf("deviation = %v, want it to shrink below %v", got.Deviation, rating.Deviation)
+			}
+			if got.Conservative != got.Rating-2*got.Deviation {
+				t.Errorf("conservative rating = %v, want %v", got.Conservative, got.Rating-2*got.Deviation)
+			}
+			if got.Plays != 1 || !got.LastPlayedAt.Equal(now) {
+				t.Errorf("plays = %d, last played at %v, want 1 and %v", got.Plays, got.LastPlayedAt, now)
+			}
+		})
+	}
+}
+
+func TestRateScoreWithoutOpponents(t *testing.T) {
+	now := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	rating := NewRating(primitive.NewObjectID(), "pong")
+	score := models.Score{Owner: rating.UserID, Value: 10, CreatedAt: now}
+
+	got := rateScore(rating, models.GameType{GameCode: "pong"}, score, nil, nil)
+	if got.Rating != rating.Rating || got.Deviation != rating.Deviation {
+		t.Errorf("rating = %v ± %v, want it unchanged at %v ± %v", got.Rating, got.Deviation, rating.Rating, rating.Deviation)
+	}
+	if got.Plays != 1 {
+		t.Errorf("plays = %d, want 1", got.Plays)
+	}
+}
+
+func TestRateScoreWeighsOpponentRatings(t *testing.T) {
+	now := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	gameType := models.GameType{GameCode: "pong", SortDirection: models.SortDescending}
+	player := NewRating(primitive.NewObjectID(), gameType.GameCode)
+	opponent := primitive.NewObjectID()
+	score := models.Score{Owner: player.UserID, Value: 30, CreatedAt: now}
+	recent := []models.Score{{Owner: opponent, Value: 20}}
+
+	weak := NewRating(opponent, gameType.GameCode)
+	weak.Rating, weak.Deviation, weak.LastPlayedAt = 1200, 60, now
+	strong := weak
+	strong.Rating = 1800
+
+	beatWeak := rateScore(player, gameType, score, recent, map[ratingKey]models.Rating{{opponent, gameType.GameCode}: weak})
+	beatStrong := rateScore(player, gameType, score, recent, map[ratingKey]models.Rating{{opponent, gameType.GameCode}: strong})
+	if beatStrong.Rating <= beatWeak.Rating {
+		t.Errorf("beating a 1800 player gave %v, beating a 1200 player %v, want the former higher", beatStrong.Rating, beatWeak.Rating)
+	}
+}
+
+func TestCurrentDeviation(t *testing.T) {
+	now := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	rating := models.Rating{Deviation: 50, LastPlayedAt: now}
+
+	tests := []struct {
+		name  string
+		at    time.Time
+		check func(float64) bool
+	}{
+		{"just played", now, func(d float64) bool { return d == 50 }},
+		{"grows while inactive", now.Add(10 * ratingPeriod), func(d float64) bool { return d > 50 && d < DefaultDeviation }},
+		{"capped at the default", now.Add(1000 * ratingPeriod), func(d float64) bool { return d == DefaultDeviation }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CurrentDeviation(rating, tt.at); !tt.check(got) {
+				t.Errorf("CurrentDeviation() = %v", got)
+			}
+		})
+	}
+}
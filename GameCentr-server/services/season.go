@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"netgames-go-server/db"
+	"netgames-go-server/models"
+)
+
+// SeasonStandingsSize is the number of entries frozen per game when a season is archived
+const SeasonStandingsSize = 1000
+
+var ErrSeasonOverlap = errors.New("season overlaps another season that includes the same games")
+
+// ActiveSeason returns the season that scores of the game posted at now
+// belong to, or nil if there is none
+func ActiveSeason(ctx context.Context, gameCode string, now time.Time) (*models.Season, error) {
+	var season models.Season
+	err := db.SeasonColl.FindOne(ctx, bson.M{
+		"starts_at": bson.M{"$lte": now},
+		"ends_at":   bson.M{"$gt": now},
+		"$or": bson.A{
+			bson.M{"game_codes": bson.M{"$size": 0}},
+			bson.M{"game_codes": gameCode},
+		},
+	}).Decode(&season)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &season, nil
+}
+
+// CheckSeasonOverlap returns ErrSeasonOverlap if another season shares a game
+// with the season and runs at the same time, so every score has one season
+func CheckSeasonOverlap(ctx context.Context, season models.Season) error {
+	filter := bson.M{
+		"_id":       bson.M{"$ne": season.ID},
+		"starts_at": bson.M{"$lt": season.EndsAt},
+		"ends_at":   bson.M{"$gt": season.StartsAt},
+	}
+	// A season without games includes every game and clashes with any other season
+	if len(season.GameCodes) > 0 {
+		filter["$or"] = bson.A{
+			bson.M{"game_codes": bson.M{"$size": 0}},
+			bson.M{"game_codes": bson.M{"$in": season.GameCodes}},
+		}
+	}
+
+	count, err := db.SeasonColl.CountDocuments(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrSeasonOverlap
+	}
+	return nil
+}
+
+// SeasonGameTypes returns the game types included in a season
+func SeasonGameTypes(ctx context.Context, season models.Season) ([]models.GameType, error) {
+	filter := bson.M{}
+	if len(season.GameCodes) > 0 {
+		filter["game_code"] = bson.M{"$in": season.GameCodes}
+	}
+
+	cursor, err := db.GameTypeColl.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var gameTypes []models.GameType
+	if err := cursor.All(ctx, &gameTypes); err != nil {
+		return nil, err
+	}
+	return gameTypes, nil
+}
+
+// ArchiveSeason freezes the final standings of every game in the season and
+// marks the season as archived. It is safe to run again for the same season.
+func ArchiveSeason(ctx context.Context, season models.Season) error {
+	gameTypes, err := SeasonGameTypes(ctx, season)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, gameType := range gameTypes {
+		filter := ScoreFilter(gameType.GameCode, SeasonTimeFrame(season))
+
+		entries, err := TopEntries(ctx, filter, gameType, SeasonStandingsSize, true)
+		if err != nil {
+			return err
+		}
+		total, err := CountPlayers(ctx, filter, gameType, bson.M{})
+		if err != nil {
+			return err
+		}
+
+		_, err = db.SeasonStandingColl.UpdateOne(
+			ctx,
+			bson.M{"season_id": season.ID, "game_code": gameType.GameCode},
+			bson.M{"$set": bson.M{"entries": entries, "total_players": total, "archived_at": now}},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = db.SeasonColl.UpdateOne(
+		ctx,
+		bson.M{"_id": season.ID},
+		bson.M{"$set": bson.M{"archived_at": now, "updated_at": now}},
+	)
+	return err
+}
+
+// ArchiveEndedSeasons archives every season that has ended but was not archived yet
+func ArchiveEndedSeasons(ctx context.Context) error {
+	cursor, err := db.SeasonColl.Find(ctx, bson.M{
+		"ends_at":     bson.M{"$lte": time.Now()},
+		"archived_at": bson.M{"$exists": false},
+	})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var seasons []models.Season
+	if err := cursor.All(ctx, &seasons); err != nil {
+		return err
+	}
+
+	for _, season := range seasons {
+		if err := ArchiveSeason(ctx, season); err != nil {
+			return err
+		}
+		log.Printf("Archived standings of season %s", season.Name)
+	}
+	return nil
+}
+
+// StartSeasonScheduler periodically archives the standings of seasons that ended
+func StartSeasonScheduler(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			if err := ArchiveEndedSeasons(ctx); err != nil {
+				log.Printf("Error archiving seasons: %v", err)
+			}
+			cancel()
+		}
+	}()
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"time"
+	_ "time/tzdata" // Resolve IANA timezones on hosts without a zoneinfo database
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"netgames-go-server/models"
+)
+
+var (
+	ErrInvalidTimeFrame = errors.New("invalid timeframe, expected all, daily, weekly, monthly, last24h or last7d")
+	ErrInvalidTimeZone  = errors.New("invalid timezone, expected an IANA name such as Europe/Berlin")
+)
+
+// Calendar timeframes cover the current day, ISO week (starting on Monday) or
+// month in the caller's timezone. Rolling timeframes cover a fixed duration
+// up to now.
+const (
+	TimeFrameAll     = "all"
+	TimeFrameDaily   = "daily"
+	TimeFrameWeekly  = "weekly"
+	TimeFrameMonthly = "monthly"
+	TimeFrameLast24h = "last24h"
+	TimeFrameLast7d  = "last7d"
+	TimeFrameSeason  = "season"
+)
+
+// MaterializedTimeFrames are the timeframes kept up to date in the leaderboards
+// collection. They are materialized for UTC only.
+var MaterializedTimeFrames = []string{TimeFrameAll, TimeFrameDaily, TimeFrameWeekly, TimeFrameMonthly}
+
+// TimeFrame is the period of scores a leaderboard ranks
+type TimeFrame struct {
+	Name     string
+	Location *time.Location
+	Start    time.Time          // Zero when the timeframe has no lower bound
+	Season   primitive.ObjectID // Set for season leaderboards, which rank the scores tagged with the season
+}
+
+// ParseTimeFrame resolves a timeframe name in the given IANA timezone, which
+// defaults to UTC when empty
+func ParseTimeFrame(name, timeZone string, now time.Time) (TimeFrame, error) {
+	location := time.UTC
+	if timeZone != "" {
+		var err error
+		location, err = time.LoadLocation(timeZone)
+		if err != nil {
+			return TimeFrame{}, ErrInvalidTimeZone
+		}
+	}
+
+	now = now.In(location)
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+
+	timeFrame := TimeFrame{Name: name, Location: location}
+	switch name {
+	case TimeFrameAll:
+	case TimeFrameDaily:
+		timeFrame.Start = startOfDay
+	case TimeFrameWeekly:
+		// time.Weekday counts from Sunday, ISO weeks start on Monday
+		daysSinceMonday := (int(now.Weekday()) + 6) % 7
+		timeFrame.Start = startOfDay.AddDate(0, 0, -daysSinceMonday)
+	case TimeFrameMonthly:
+		timeFrame.Start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, location)
+	case TimeFrameLast24h:
+		timeFrame.Start = now.Add(-24 * time.Hour)
+	case TimeFrameLast7d:
+		timeFrame.Start = now.Add(-7 * 24 * time.Hour)
+	default:
+		return TimeFrame{}, ErrInvalidTimeFrame
+	}
+
+	return timeFrame, nil
+}
+
+// SeasonTimeFrame returns the timeframe covering the scores posted during a season
+func SeasonTimeFrame(season models.Season) TimeFrame {
+	return TimeFrame{Name: TimeFrameSeason, Location: time.UTC, Season: season.ID}
+}
+
+// Materialized reports whether the timeframe is served from the leaderboards collection
+func (t TimeFrame) Materialized() bool {
+	return t.Location == time.UTC && slices.Contains(MaterializedTimeFrames, t.Name)
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeFrame(t *testing.T) {
+	// A Wednesday, 23:30 UTC, which is already Thursday in Berlin
+	now := time.Date(2026, 4, 29, 23, 30, 0, 0, time.UTC)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		timeFrame string
+		timeZone  string
+		wantStart time.Time
+		wantErr   error
+	}{
+		{"all", TimeFrameAll, "", time.Time{}, nil},
+		{"daily", TimeFrameDaily, "", time.Date(2026, 4, 29, 0, 0, 0, 0, time.UTC), nil},
+		{"daily in a timezone", TimeFrameDaily, "Europe/Berlin", time.Date(2026, 4, 30, 0, 0, 0, 0, berlin), nil},
+		{"weekly starts on monday", TimeFrameWeekly, "", time.Date(2026, 4, 27, 0, 0, 0, 0, time.UTC), nil},
+		{"monthly", TimeFrameMonthly, "", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), nil},
+		{"last 24 hours", TimeFrameLast24h, "", now.Add(-24 * time.Hour), nil},
+		{"last 7 days", TimeFrameLast7d, "", now.Add(-7 * 24 * time.Hour), nil},
+		{"unknown timeframe", "yearly", "", time.Time{}, ErrInvalidTimeFrame},
+		{"unknown timezone", TimeFrameDaily, "Mars/Olympus_Mons", time.Time{}, ErrInvalidTimeZone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeFrame, err := ParseTimeFrame(tt.timeFrame, tt.timeZone, now)
+			if err != tt.wantErr {
+				t.Fatalf("ParseTimeFrame() error = %v, want %v", err, tt.wantErr)
+			}
+			if !timeFrame.Start.Equal(tt.wantStart) {
+				t.Errorf("ParseTimeFrame() start = %v, want %v", timeFrame.Start, tt.wantStart)
+			}
+		})
+	}
+}
+
+func TestParseTimeFrameWeeklyOnSunday(t *testing.T) {
+	// Sunday still belongs to the ISO week that started six days earlier
+	now := time.Date(2026, 5, 3, 12, 0, 0, 0, time.UTC)
+	timeFrame, err := ParseTimeFrame(TimeFrameWeekly, "", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 4, 27, 0, 0, 0, 0, time.UTC); !timeFrame.Start.Equal(want) {
+		t.Errorf("ParseTimeFrame() start = %v, want %v", timeFrame.Start, want)
+	}
+}
+
+func TestTimeFrameMaterialized(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		timeFrame string
+		timeZone  string
+		want      bool
+	}{
+		{TimeFrameDaily, "", true},
+		{TimeFrameAll, "", true},
+		{TimeFrameDaily, "Europe/Berlin", false},
+		{TimeFrameLast24h, "", false},
+	}
+
+	for _, tt := range tests {
+		timeFrame, err := ParseTimeFrame(tt.timeFrame, tt.timeZone, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := timeFrame.Materialized(); got != tt.want {
+			t.Errorf("%s in %q: Materialized() = %v, want %v", tt.timeFrame, tt.timeZone, got, tt.want)
+		}
+	}
+}